@@ -3,13 +3,13 @@ package micrologger
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"github.com/giantswarm/microerror"
 )
 
 const (
 	KeyLevel     = "level"
+	KeyName      = "name"
 	KeyVerbosity = "verbosity"
 )
 
@@ -18,6 +18,7 @@ const (
 	levelInfo
 	levelWarning
 	levelError
+	levelFatal
 )
 
 var (
@@ -26,6 +27,7 @@ var (
 		"info":    levelInfo,
 		"warning": levelWarning,
 		"error":   levelError,
+		"fatal":   levelFatal,
 	}
 )
 
@@ -35,12 +37,31 @@ type ActivationLoggerConfig struct {
 	Underlying Logger
 
 	Activations map[string]interface{}
+
+	// FlightRecorderSize enables the flight recorder when greater than zero.
+	// Records suppressed by the activations are then kept in a ring buffer of
+	// this size. As soon as an error level record passes the activations,
+	// the buffered records of its scope are dispatched first, marked with
+	// "buffered": true. Records of scopes without errors are discarded.
+	FlightRecorderSize int
+	// FlightRecorderKey is the loggermeta key used to scope the flight
+	// recorder, e.g. a request ID. Records without this key share the scope
+	// of the logger.
+	FlightRecorderKey string
+
+	// ErrorHandler is called when activations cannot be checked. Defaults to
+	// DefaultErrorHandler.
+	ErrorHandler func(err error, keyVals []interface{})
 }
 
 type activationLogger struct {
 	underlying Logger
 
 	activations map[string]interface{}
+	name        string
+	recorder    *flightRecorder
+
+	errorHandler func(err error, keyVals []interface{})
 }
 
 // NewActivation creates a new activation key logger. This logger kind can be
@@ -59,25 +80,50 @@ type activationLogger struct {
 //	emitted logging call is going to be ignored.
 //
 //	Filtering log levels works using the special log levels debug, info,
-//	warning and error. The level based nature of this activation mechanism is
-//	that lower log levels match just like exact log levels match. When the
-//	Logger is configured to activate on info log levels, the Logger will
-//	activate on debug related logs, as well as info related logs, but not on
-//	warning or error related logs.
+//	warning, error and fatal. The level based nature of this activation
+//	mechanism is that lower log levels match just like exact log levels
+//	match. When the Logger is configured to activate on info log levels, the
+//	Logger will activate on debug related logs, as well as info related logs,
+//	but not on warning, error or fatal related logs.
 //
 //	Filtering log verbosity works similar like the log level mechanism, but
 //	on arbitrary verbosity levels, which are represented as numbers. As long
 //	as the configured verbosity is higher or equal to the perceived verbosity
 //	obtained by the emitted logging call, the log will be dispatched.
+//
+// Loggers created using Named match the activation key "name" against their
+// dotted name.
+//
+// Logging calls made with a context.Context escalated using WithDebug bypass
+// the level and verbosity activations. All other activation keys still have
+// to match.
+//
+// Optionally the activation key logger can act as flight recorder. See
+// ActivationLoggerConfig.FlightRecorderSize for details.
 func NewActivation(config ActivationLoggerConfig) (Logger, error) {
 	if config.Underlying == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Underlying must not be empty", config)
 	}
+	if config.FlightRecorderSize < 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.FlightRecorderSize must not be negative", config)
+	}
+
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = DefaultErrorHandler
+	}
+
+	var recorder *flightRecorder
+	if config.FlightRecorderSize > 0 {
+		recorder = newFlightRecorder(config.FlightRecorderKey, config.FlightRecorderSize)
+	}
 
 	l := &activationLogger{
-		underlying: config.Underlying.WithIncreasedCallerDepth(),
+		underlying: config.Underlying,
 
 		activations: config.Activations,
+		recorder:    recorder,
+
+		errorHandler: config.ErrorHandler,
 	}
 
 	return l, nil
@@ -88,15 +134,17 @@ func (l *activationLogger) Debug(ctx context.Context, message string) {
 }
 
 func (l *activationLogger) Debugf(ctx context.Context, format string, params ...interface{}) {
+	if !l.Enabled(ctx, "debug") {
+		return
+	}
 	l.Debug(ctx, fmt.Sprintf(format, params...))
 }
 
+// Error logs the given error like the underlying logger would, so that its
+// ErrorLevels apply to activations as well.
 func (l *activationLogger) Error(ctx context.Context, err error, message string) {
-	if err != nil {
-		l.LogCtx(ctx, "level", "error", "message", message, "stack", microerror.JSON(err))
-	} else {
-		l.LogCtx(ctx, "level", "error", "message", message)
-	}
+	level, skipStack := l.errorLevel(err)
+	l.LogCtx(ctx, errorKeyVals(level, message, err, skipStack)...)
 }
 
 func (l *activationLogger) Errorf(ctx context.Context, err error, format string, params ...interface{}) {
@@ -104,24 +152,104 @@ func (l *activationLogger) Errorf(ctx context.Context, err error, format string,
 }
 
 func (l *activationLogger) Log(keyVals ...interface{}) {
-	activated, err := shouldActivate(l.activations, keyVals)
+	activated, err := shouldActivate(l.activations, l.keyValsWithName(keyVals))
 	if err != nil {
-		log.Printf("failed to check activated, reason: %#q", err.Error())
+		l.errorHandler(microerror.Mask(err), keyVals)
 	}
 
 	if activated {
+		l.dump(nil, keyVals)
 		l.underlying.Log(keyVals...)
+	} else if l.recorder != nil {
+		l.recorder.record(nil, l.keyValsWithName(keyVals), l.originKeyVals())
 	}
 }
 
 func (l *activationLogger) LogCtx(ctx context.Context, keyVals ...interface{}) {
-	activated, err := shouldActivate(l.activations, keyVals)
+	l.logCtx(ctx, keyVals, func() {
+		l.underlying.LogCtx(ctx, keyVals...)
+	})
+}
+
+// LogFields decides like LogCtx. Activated records are passed on to the
+// underlying logger as typed fields.
+func (l *activationLogger) LogFields(ctx context.Context, level string, message string, fields ...Field) {
+	l.logCtx(ctx, fieldKeyVals(level, message, fields), func() {
+		l.underlying.LogFields(ctx, level, message, fields...)
+	})
+}
+
+// logCtx decides whether the record of the given keyVals is activated. Then
+// write is called to write it using the underlying logger. Otherwise it is
+// buffered by the flight recorder, if any.
+func (l *activationLogger) logCtx(ctx context.Context, keyVals []interface{}, write func()) {
+	activate := shouldActivate
+	if IsDebug(ctx) {
+		activate = shouldActivateDebug
+	}
+
+	activated, err := activate(l.activations, l.keyValsWithName(keyVals))
 	if err != nil {
-		log.Printf("failed to check activated, reason: %#q", err.Error())
+		l.errorHandler(microerror.Mask(err), keyVals)
 	}
 
 	if activated {
-		l.underlying.LogCtx(ctx, keyVals...)
+		l.dump(ctx, keyVals)
+		write()
+	} else if l.recorder != nil {
+		l.recorder.record(ctx, l.keyValsWithName(keyVals), l.originKeyVals())
+	}
+}
+
+// Enabled returns whether records of the given level may be activated by calls
+// made with ctx and are written by the underlying logger. Activation keys
+// other than level and name are only known when logging and are assumed to
+// match. With a flight recorder all records may be written.
+func (l *activationLogger) Enabled(ctx context.Context, level string) bool {
+	if !l.underlying.Enabled(ctx, level) {
+		return false
+	}
+	if l.recorder != nil {
+		return true
+	}
+	if len(l.activations) == 0 {
+		return false
+	}
+
+	known := map[string]interface{}{}
+	for aKey, aVal := range l.activations {
+		if aKey == KeyLevel || aKey == KeyName {
+			known[aKey] = aVal
+		}
+	}
+	if len(known) == 0 {
+		return true
+	}
+
+	activate := shouldActivate
+	if IsDebug(ctx) {
+		activate = shouldActivateDebug
+	}
+
+	activated, err := activate(known, l.keyValsWithName([]interface{}{KeyLevel, level}))
+	if err != nil {
+		l.errorHandler(microerror.Mask(err), nil)
+	}
+
+	return activated
+}
+
+// Named returns a copy of the logger whose name is matched against the
+// activation key "name" in addition. See Logger.Named.
+func (l *activationLogger) Named(name string) Logger {
+	return &activationLogger{
+		underlying: l.underlying.Named(name),
+
+		activations: l.activations,
+		name:        joinName(l.name, name),
+		recorder:    l.recorder,
+
+		errorHandler: l.errorHandler,
 	}
 }
 
@@ -133,6 +261,99 @@ func (l *activationLogger) WithIncreasedCallerDepth() Logger {
 	return l.underlying.WithIncreasedCallerDepth()
 }
 
+// flushAndExit exits like the underlying logger does. See exiter.
+func (l *activationLogger) flushAndExit(code int) {
+	if e, ok := l.underlying.(exiter); ok {
+		e.flushAndExit(code)
+	} else {
+		exit(code)
+	}
+}
+
+// errorLevel classifies err like the underlying logger does. Errors of loggers
+// unable to classify them are logged in error level with stack.
+func (l *activationLogger) errorLevel(err error) (string, bool) {
+	if c, ok := l.underlying.(errorClassifier); ok {
+		return c.errorLevel(err)
+	}
+
+	return classifyError(nil, err)
+}
+
+// originKeyVals returns the time and caller keyVals of the current logging
+// call, which are preserved for records buffered by the flight recorder.
+func (l *activationLogger) originKeyVals() []interface{} {
+	var kvs []interface{}
+	{
+		kvs = append(kvs, l.timeKeyVals()...)
+		kvs = append(kvs, l.callerKeyVals()...)
+	}
+
+	return kvs
+}
+
+// timeKeyVals returns the time keyVals of the current logging call as
+// rendered by the underlying logger. Loggers unable to render them get the
+// time in the default format.
+func (l *activationLogger) timeKeyVals() []interface{} {
+	if r, ok := l.underlying.(timeResolver); ok {
+		return r.timeKeyVals()
+	}
+
+	return []interface{}{"time", DefaultTimestampFormatter()}
+}
+
+// callerKeyVals returns the caller keyVals of the current logging call as
+// rendered by the underlying logger. Loggers unable to render them get the
+// caller in the default format.
+func (l *activationLogger) callerKeyVals() []interface{} {
+	if r, ok := l.underlying.(callerResolver); ok {
+		return r.callerKeyVals()
+	}
+
+	return []interface{}{KeyCaller, callerFormatter{}.caller(findCaller(0, nil))}
+}
+
+// keyValsWithName returns keyVals with the name of the logger appended, unless
+// the logger is unnamed.
+func (l *activationLogger) keyValsWithName(keyVals []interface{}) []interface{} {
+	if l.name == "" {
+		return keyVals
+	}
+
+	var kvs []interface{}
+	{
+		kvs = append(kvs, keyVals...)
+		kvs = append(kvs, KeyName, l.name)
+	}
+
+	return kvs
+}
+
+// dump dispatches the records buffered by the flight recorder in the scope of
+// ctx in case the given keyVals are logged in error or fatal level. Buffered
+// records are escalated using WithDebug, so that they are not dropped by the
+// level configured for the underlying logger.
+func (l *activationLogger) dump(ctx context.Context, keyVals []interface{}) {
+	if l.recorder == nil {
+		return
+	}
+	v, _ := valueFor(keyVals, KeyLevel)
+	s, _ := v.(string)
+	if levelMapping[s] < levelError {
+		return
+	}
+
+	for _, r := range l.recorder.flush(ctx) {
+		ctx := r.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		l.underlying.LogCtx(WithDebug(ctx), append(r.keyVals, KeyBuffered, true)...)
+	}
+}
+
 func valueFor(keyVals []interface{}, key string) (interface{}, bool) {
 	for i := 1; i < len(keyVals); i += 2 {
 		if key == keyVals[i-1] {
@@ -226,3 +447,21 @@ func shouldActivate(activations map[string]interface{}, keyVals []interface{}) (
 
 	return false, nil
 }
+
+// shouldActivateDebug is like shouldActivate but ignores level and verbosity
+// activations, which are always considered to match.
+func shouldActivateDebug(activations map[string]interface{}, keyVals []interface{}) (bool, error) {
+	remaining := map[string]interface{}{}
+	for aKey, aVal := range activations {
+		if aKey == KeyLevel || aKey == KeyVerbosity {
+			continue
+		}
+		remaining[aKey] = aVal
+	}
+
+	if len(remaining) == 0 {
+		return len(activations) != 0, nil
+	}
+
+	return shouldActivate(remaining, keyVals)
+}
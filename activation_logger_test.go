@@ -436,3 +436,131 @@ func Test_ActivationKeyLogger_shouldActivate_verbosity(t *testing.T) {
 		}
 	}
 }
+
+func Test_ActivationKeyLogger_shouldActivateDebug(t *testing.T) {
+	testCases := []struct {
+		Activations    map[string]interface{}
+		KeyVals        []interface{}
+		ExpectedResult bool
+	}{
+		// Case 0, zero value input results into false just like for
+		// shouldActivate.
+		{
+			Activations:    nil,
+			KeyVals:        nil,
+			ExpectedResult: false,
+		},
+
+		// Case 1, level activations are bypassed.
+		{
+			Activations: map[string]interface{}{
+				"level": "error",
+			},
+			KeyVals: []interface{}{
+				"level",
+				"debug",
+				"message",
+				"test",
+			},
+			ExpectedResult: true,
+		},
+
+		// Case 2, verbosity activations are bypassed.
+		{
+			Activations: map[string]interface{}{
+				"verbosity": 1,
+			},
+			KeyVals: []interface{}{
+				"level",
+				"debug",
+				"verbosity",
+				5,
+			},
+			ExpectedResult: true,
+		},
+
+		// Case 3, arbitrary activations still have to match.
+		{
+			Activations: map[string]interface{}{
+				"level": "error",
+				"foo":   "bar",
+			},
+			KeyVals: []interface{}{
+				"level",
+				"debug",
+				"foo",
+				"baz",
+			},
+			ExpectedResult: false,
+		},
+
+		// Case 4, same as 3 but with matching arbitrary activations.
+		{
+			Activations: map[string]interface{}{
+				"level": "error",
+				"foo":   "bar",
+			},
+			KeyVals: []interface{}{
+				"level",
+				"debug",
+				"foo",
+				"bar",
+			},
+			ExpectedResult: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		result, err := shouldActivateDebug(tc.Activations, tc.KeyVals)
+		if err != nil {
+			t.Fatalf("case %d expected %#v got %#v", i, nil, err)
+		}
+
+		if result != tc.ExpectedResult {
+			t.Fatalf("case %d expected %#v got %#v", i, tc.ExpectedResult, result)
+		}
+	}
+}
+
+func Test_ActivationKeyLogger_shouldActivate_fatal(t *testing.T) {
+	testCases := []struct {
+		Activations    map[string]interface{}
+		KeyVals        []interface{}
+		ExpectedResult bool
+	}{
+		// Case 0, fatal is above error.
+		{
+			Activations: map[string]interface{}{
+				"level": "error",
+			},
+			KeyVals: []interface{}{
+				"level",
+				"fatal",
+			},
+			ExpectedResult: true,
+		},
+
+		// Case 1, error is below fatal.
+		{
+			Activations: map[string]interface{}{
+				"level": "fatal",
+			},
+			KeyVals: []interface{}{
+				"level",
+				"error",
+			},
+			ExpectedResult: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		result, err := shouldActivate(tc.Activations, tc.KeyVals)
+		if err != nil {
+			t.Fatalf("case %d expected %#v got %#v", i, nil, err)
+		}
+
+		if result != tc.ExpectedResult {
+			t.Fatalf("case %d expected %#v got %#v", i, tc.ExpectedResult, result)
+		}
+	}
+}
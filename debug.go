@@ -0,0 +1,29 @@
+package micrologger
+
+import (
+	"context"
+)
+
+// debugKey is an unexported type for the debug escalation key defined in this
+// package. This prevents collisions with keys defined in other packages.
+type debugKey struct{}
+
+// WithDebug returns a new context.Context which escalates logging calls made
+// with it to debug. Loggers let through records of all levels and
+// verbosities for such calls, regardless of their configured level,
+// activation levels or activation verbosity. This is useful to debug a single
+// request or reconciliation without raising the level of the whole process.
+func WithDebug(ctx context.Context) context.Context {
+	return context.WithValue(ctx, debugKey{}, true)
+}
+
+// IsDebug returns whether the given context.Context was escalated to debug
+// using WithDebug.
+func IsDebug(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+
+	v, _ := ctx.Value(debugKey{}).(bool)
+	return v
+}
@@ -0,0 +1,63 @@
+package micrologger
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func Test_MicroLogger_WithDebug(t *testing.T) {
+	testCases := []struct {
+		name          string
+		level         string
+		ctx           context.Context
+		expectedLines int
+	}{
+		{
+			name:          "case 0: no level writes debug records",
+			level:         "",
+			ctx:           context.Background(),
+			expectedLines: 2,
+		},
+		{
+			name:          "case 1: error level drops debug records",
+			level:         "error",
+			ctx:           context.Background(),
+			expectedLines: 1,
+		},
+		{
+			name:          "case 2: debug context escalates records",
+			level:         "error",
+			ctx:           WithDebug(context.Background()),
+			expectedLines: 2,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &bytes.Buffer{}
+
+			logger, err := New(Config{IOWriter: w, Level: tc.level})
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			logger.Debug(tc.ctx, "test")
+			logger.Error(tc.ctx, nil, "test")
+
+			lines := strings.Count(w.String(), "\n")
+			if lines != tc.expectedLines {
+				t.Fatalf("lines = %d, want %d", lines, tc.expectedLines)
+			}
+		})
+	}
+}
+
+func Test_MicroLogger_invalidLevel(t *testing.T) {
+	_, err := New(Config{Level: "verbose"})
+	if !IsInvalidConfig(err) {
+		t.Fatalf("err = %v, want %v", err, invalidConfigError)
+	}
+}
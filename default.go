@@ -1,16 +1,26 @@
 package micrologger
 
 import (
+	"log"
 	"os"
 	"time"
-
-	kitlog "github.com/go-kit/log"
 )
 
-var DefaultCaller = newCallerFunc(0)
+var DefaultCaller = newCallerFunc(0, callerFormatter{})
 
 var DefaultIOWriter = os.Stdout
 
-var DefaultTimestampFormatter kitlog.Valuer = func() interface{} {
+var DefaultTimestampFormatter = func() interface{} {
 	return time.Now().UTC().Format("2006-01-02T15:04:05.999999-07:00")
 }
+
+// DefaultLogrLevel writes all Info records of LogrSink in debug level.
+var DefaultLogrLevel = func(verbosity int) string {
+	return "debug"
+}
+
+// DefaultErrorHandler writes failures using the standard library's log
+// package.
+var DefaultErrorHandler = func(err error, keyVals []interface{}) {
+	log.Printf("failed to log with error: %#q, keyVals = %v", err.Error(), keyVals)
+}
@@ -0,0 +1,186 @@
+package micrologger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func Test_Logger_Enabled(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      Config
+		activations map[string]interface{}
+		recorder    bool
+		logger      func(l Logger) Logger
+		ctx         context.Context
+		level       string
+		expected    bool
+	}{
+		{
+			name:     "case 0: no level configured",
+			level:    "debug",
+			expected: true,
+		},
+		{
+			name:     "case 1: level below configured level",
+			config:   Config{Level: "info"},
+			level:    "debug",
+			expected: false,
+		},
+		{
+			name:     "case 2: level above configured level",
+			config:   Config{Level: "info"},
+			level:    "warning",
+			expected: true,
+		},
+		{
+			name:     "case 3: debug context",
+			config:   Config{Level: "info"},
+			ctx:      WithDebug(context.Background()),
+			level:    "debug",
+			expected: true,
+		},
+		{
+			name:        "case 4: level below activation level",
+			activations: map[string]interface{}{KeyLevel: "info"},
+			level:       "debug",
+			expected:    false,
+		},
+		{
+			name:        "case 5: level above activation level",
+			activations: map[string]interface{}{KeyLevel: "info"},
+			level:       "error",
+			expected:    true,
+		},
+		{
+			name:        "case 6: activation keys only known when logging",
+			activations: map[string]interface{}{KeyLevel: "info", "foo": "bar"},
+			level:       "info",
+			expected:    true,
+		},
+		{
+			name:        "case 7: name not matching activation name",
+			activations: map[string]interface{}{KeyName: "resource"},
+			logger:      func(l Logger) Logger { return l.Named("other") },
+			level:       "error",
+			expected:    false,
+		},
+		{
+			name:        "case 8: name matching activation name",
+			activations: map[string]interface{}{KeyName: "resource", KeyLevel: "debug"},
+			logger:      func(l Logger) Logger { return l.Named("resource") },
+			level:       "debug",
+			expected:    true,
+		},
+		{
+			name:        "case 9: flight recorder",
+			activations: map[string]interface{}{KeyLevel: "info"},
+			recorder:    true,
+			level:       "debug",
+			expected:    true,
+		},
+		{
+			name:        "case 10: underlying level",
+			config:      Config{Level: "error"},
+			activations: map[string]interface{}{KeyLevel: "debug"},
+			level:       "info",
+			expected:    false,
+		},
+		{
+			name:        "case 11: debug context bypasses activation level",
+			activations: map[string]interface{}{KeyLevel: "info"},
+			ctx:         WithDebug(context.Background()),
+			level:       "debug",
+			expected:    true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			config := tc.config
+			config.IOWriter = &bytes.Buffer{}
+
+			var logger Logger
+			logger, err := New(config)
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			if tc.activations != nil {
+				c := ActivationLoggerConfig{
+					Underlying:  logger,
+					Activations: tc.activations,
+				}
+				if tc.recorder {
+					c.FlightRecorderSize = 1
+				}
+
+				logger, err = NewActivation(c)
+				if err != nil {
+					t.Fatalf("err = %v, want %v", err, nil)
+				}
+			}
+
+			if tc.logger != nil {
+				logger = tc.logger(logger)
+			}
+
+			ctx := tc.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			enabled := logger.Enabled(ctx, tc.level)
+			if enabled != tc.expected {
+				t.Fatalf("enabled = %v, want %v", enabled, tc.expected)
+			}
+		})
+	}
+}
+
+func Test_Lazy(t *testing.T) {
+	w := &bytes.Buffer{}
+
+	logger, err := New(Config{IOWriter: w, Level: "info"})
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	var count int
+	lazy := Lazy(func() interface{} {
+		count++
+		return count
+	})
+
+	l := logger.With("with", lazy)
+
+	l.LogCtx(context.Background(), "level", "debug", "message", "dropped", "log", lazy)
+	if count != 0 {
+		t.Fatalf("count = %d, want %d", count, 0)
+	}
+
+	l.LogCtx(context.Background(), "level", "info", "message", "test", "log", lazy, "err", Lazy(func() interface{} {
+		return errors.New("test error")
+	}))
+	if count != 2 {
+		t.Fatalf("count = %d, want %d", count, 2)
+	}
+
+	var entry map[string]interface{}
+	err = json.Unmarshal(w.Bytes(), &entry)
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	if entry["with"] == entry["log"] {
+		t.Fatalf("with = %v, want different from log = %v", entry["with"], entry["log"])
+	}
+	e, _ := entry["err"].(map[string]interface{})
+	if e["message"] != "test error" {
+		t.Fatalf("err = %#v, want message %#q", entry["err"], "test error")
+	}
+}
@@ -0,0 +1,459 @@
+package micrologger
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/giantswarm/microerror"
+	kitlog "github.com/go-kit/log"
+)
+
+// jsonLogger is a kitlog.Logger writing every record as a single JSON object
+// followed by a newline, using exactly one call to Write. Its output is byte
+// identical to the one of kitlog.NewJSONLogger: keys are sorted, later keys
+// win and missing values are written as "(MISSING)". Common value types are
+// encoded without reflection into pooled buffers. All other values are
+// encoded using encoding/json.
+//
+// Encoding failures are returned as encodeFailedError and nothing is written.
+// Write failures are returned as writeFailedError after the record is written
+// to the fallback writer, if any.
+type jsonLogger struct {
+	w        io.Writer
+	fallback io.Writer
+}
+
+// jsonEncoder encodes records. Its entries are fields, so that typed fields of
+// records are encoded without converting their values to interface{}.
+type jsonEncoder struct {
+	buf     []byte
+	entries []Field
+}
+
+var jsonEncoderPool = sync.Pool{
+	New: func() interface{} {
+		return &jsonEncoder{
+			buf:     make([]byte, 0, 1024),
+			entries: make([]Field, 0, 16),
+		}
+	},
+}
+
+// maxPooledBuffer is the capacity up to which buffers are returned to the
+// pool, so that single huge records do not pin memory.
+const maxPooledBuffer = 64 * 1024
+
+func newJSONLogger(w io.Writer, fallback io.Writer) *jsonLogger {
+	return &jsonLogger{w: w, fallback: fallback}
+}
+
+func (l *jsonLogger) Log(keyVals ...interface{}) error {
+	e := jsonEncoderPool.Get().(*jsonEncoder)
+	defer e.free()
+
+	e.reset()
+	for i := 0; i < len(keyVals); i += 2 {
+		var v interface{} = kitlog.ErrMissingValue
+		if i+1 < len(keyVals) {
+			v = keyVals[i+1]
+		}
+		e.add(keyVals[i], v)
+	}
+
+	return l.write(e)
+}
+
+// LogRecord writes the given record like Log writes the key-value pairs
+// returned by Record.KeyVals. Typed fields are encoded directly.
+func (l *jsonLogger) LogRecord(r *Record) error {
+	e := jsonEncoderPool.Get().(*jsonEncoder)
+	defer e.free()
+
+	e.reset()
+	if r.Caller != "" {
+		e.addField(String(KeyCaller, r.Caller))
+	}
+	for _, f := range r.Fields {
+		e.addField(f)
+	}
+	if r.Level != "" {
+		e.addField(String(KeyLevel, r.Level))
+	}
+	if r.Message != "" {
+		e.addField(String("message", r.Message))
+	}
+
+	return l.write(e)
+}
+
+// logKeyVals writes the record of the given keyVals followed by the given
+// fields exactly like LogRecord writes the record newRecord creates from them,
+// without creating it.
+func (l *jsonLogger) logKeyVals(keyVals []interface{}, fields ...[]Field) error {
+	e := jsonEncoderPool.Get().(*jsonEncoder)
+	defer e.free()
+
+	e.reset()
+
+	// The last string values of the "level", "message" and "caller" keys are
+	// held back like newRecord does. The caller is written before all other
+	// keys and level and message after them, see LogRecord.
+	var caller, level, message string
+	for i := 0; i < len(keyVals); i += 2 {
+		key := keyString(keyVals[i])
+		var v interface{} = kitlog.ErrMissingValue
+		if i+1 < len(keyVals) {
+			v = keyVals[i+1]
+		}
+
+		if s, ok := v.(string); ok {
+			switch key {
+			case KeyCaller:
+				caller = s
+				continue
+			case KeyLevel:
+				level = s
+				continue
+			case "message":
+				message = s
+				continue
+			}
+		}
+
+		e.add(key, v)
+	}
+	for _, fs := range fields {
+		for _, f := range fs {
+			e.addField(f)
+		}
+	}
+	if caller != "" && !e.has(KeyCaller) {
+		e.addField(String(KeyCaller, caller))
+	}
+	if level != "" {
+		e.addField(String(KeyLevel, level))
+	}
+	if message != "" {
+		e.addField(String("message", message))
+	}
+
+	return l.write(e)
+}
+
+// write encodes the entries of e and writes them.
+func (l *jsonLogger) write(e *jsonEncoder) error {
+	err := e.encodeRecord()
+	if err != nil {
+		return microerror.Maskf(encodeFailedError, "%s", err.Error())
+	}
+
+	_, err = l.w.Write(e.buf)
+	if err != nil {
+		if l.fallback != nil {
+			_, fallbackErr := l.fallback.Write(e.buf)
+			if fallbackErr != nil {
+				return microerror.Maskf(writeFailedError, "%s, fallback: %s", err.Error(), fallbackErr.Error())
+			}
+		}
+		return microerror.Maskf(writeFailedError, "%s", err.Error())
+	}
+
+	return nil
+}
+
+func (e *jsonEncoder) reset() {
+	e.buf = e.buf[:0]
+	for i := range e.entries {
+		e.entries[i] = Field{}
+	}
+	e.entries = e.entries[:0]
+}
+
+// free returns e to the pool unless its buffer grew too large.
+func (e *jsonEncoder) free() {
+	if cap(e.buf) <= maxPooledBuffer {
+		jsonEncoderPool.Put(e)
+	}
+}
+
+// add adds the given key and value after converting them like go-kit/log does,
+// which prefers json.Marshaler and encoding.TextMarshaler over error and
+// fmt.Stringer.
+func (e *jsonEncoder) add(k interface{}, v interface{}) {
+	key := keyString(k)
+
+	switch x := v.(type) {
+	case json.Marshaler:
+	case encoding.TextMarshaler:
+	case error:
+		v = safeError(x)
+	case fmt.Stringer:
+		v = safeString(x)
+	}
+
+	e.insert(Object(key, v))
+}
+
+// addField adds the given field. Values of object and error fields are
+// converted like add does.
+func (e *jsonEncoder) addField(f Field) {
+	if f.kind == fieldObject || f.kind == fieldError {
+		e.add(f.Key, f.obj)
+		return
+	}
+
+	e.insert(f)
+}
+
+// has returns whether an entry of the given key was added.
+func (e *jsonEncoder) has(key string) bool {
+	for _, entry := range e.entries {
+		if entry.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// insert inserts f into the entries. Entries are kept sorted by key using
+// insertion sort, which is cheap for the small number of keys of a record.
+// Later keys replace earlier ones.
+func (e *jsonEncoder) insert(f Field) {
+	i := len(e.entries)
+	for i > 0 && e.entries[i-1].Key > f.Key {
+		i--
+	}
+	if i > 0 && e.entries[i-1].Key == f.Key {
+		e.entries[i-1] = f
+		return
+	}
+
+	e.entries = append(e.entries, Field{})
+	copy(e.entries[i+1:], e.entries[i:])
+	e.entries[i] = f
+}
+
+func (e *jsonEncoder) encodeRecord() error {
+	e.buf = append(e.buf, '{')
+	for i, entry := range e.entries {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		e.buf = appendJSONString(e.buf, entry.Key)
+		e.buf = append(e.buf, ':')
+
+		var err error
+		e.buf, err = appendJSONField(e.buf, entry)
+		if err != nil {
+			return err
+		}
+	}
+	e.buf = append(e.buf, '}', '\n')
+
+	return nil
+}
+
+// appendJSONField appends the JSON encoding of the value of f.
+func appendJSONField(buf []byte, f Field) ([]byte, error) {
+	switch f.kind {
+	case fieldString:
+		return appendJSONString(buf, f.str), nil
+	case fieldInt:
+		return strconv.AppendInt(buf, f.num, 10), nil
+	case fieldDuration:
+		return appendJSONString(buf, time.Duration(f.num).String()), nil
+	default:
+		return appendJSONValue(buf, f.obj)
+	}
+}
+
+// appendJSONValue appends the JSON encoding of v as encoding/json without HTML
+// escaping would produce it.
+func appendJSONValue(buf []byte, v interface{}) ([]byte, error) {
+	switch x := v.(type) {
+	case nil:
+		return append(buf, "null"...), nil
+	case string:
+		return appendJSONString(buf, x), nil
+	case bool:
+		return strconv.AppendBool(buf, x), nil
+	case int:
+		return strconv.AppendInt(buf, int64(x), 10), nil
+	case int8:
+		return strconv.AppendInt(buf, int64(x), 10), nil
+	case int16:
+		return strconv.AppendInt(buf, int64(x), 10), nil
+	case int32:
+		return strconv.AppendInt(buf, int64(x), 10), nil
+	case int64:
+		return strconv.AppendInt(buf, x, 10), nil
+	case uint:
+		return strconv.AppendUint(buf, uint64(x), 10), nil
+	case uint8:
+		return strconv.AppendUint(buf, uint64(x), 10), nil
+	case uint16:
+		return strconv.AppendUint(buf, uint64(x), 10), nil
+	case uint32:
+		return strconv.AppendUint(buf, uint64(x), 10), nil
+	case uint64:
+		return strconv.AppendUint(buf, x, 10), nil
+	case float32:
+		return appendJSONFloat(buf, float64(x), 32)
+	case float64:
+		return appendJSONFloat(buf, x, 64)
+	case map[string]interface{}:
+		return appendJSONMap(buf, x)
+	case []interface{}:
+		if x == nil {
+			return append(buf, "null"...), nil
+		}
+		buf = append(buf, '[')
+		for i, item := range x {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			var err error
+			buf, err = appendJSONValue(buf, item)
+			if err != nil {
+				return buf, err
+			}
+		}
+		return append(buf, ']'), nil
+	default:
+		return appendJSONReflect(buf, v)
+	}
+}
+
+func appendJSONMap(buf []byte, m map[string]interface{}) ([]byte, error) {
+	if m == nil {
+		return append(buf, "null"...), nil
+	}
+
+	// Maps of rendered errors and stack frames have few keys, so that sorting
+	// them using insertion sort on the stack is cheap.
+	var keysArray [8]string
+	keys := keysArray[:0]
+	for k := range m {
+		keys = append(keys, k)
+		for i := len(keys) - 1; i > 0 && keys[i-1] > keys[i]; i-- {
+			keys[i-1], keys[i] = keys[i], keys[i-1]
+		}
+	}
+
+	buf = append(buf, '{')
+	for i, k := range keys {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendJSONString(buf, k)
+		buf = append(buf, ':')
+
+		var err error
+		buf, err = appendJSONValue(buf, m[k])
+		if err != nil {
+			return buf, err
+		}
+	}
+
+	return append(buf, '}'), nil
+}
+
+// appendJSONFloat appends f like encoding/json does. NaN and infinite values
+// are left to encoding/json to return its error.
+func appendJSONFloat(buf []byte, f float64, bits int) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		if bits == 32 {
+			return appendJSONReflect(buf, float32(f))
+		}
+		return appendJSONReflect(buf, f)
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	buf = strconv.AppendFloat(buf, f, format, -1, bits)
+	if format == 'e' {
+		// Clean up e-09 to e-9.
+		n := len(buf)
+		if n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+
+	return buf, nil
+}
+
+// appendJSONString appends s as JSON string. Strings of printable ASCII
+// characters are appended as they are. All others are left to encoding/json,
+// which takes care of escaping control characters, invalid UTF-8 and line
+// separators.
+func appendJSONString(buf []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x20 || c >= utf8.RuneSelf || c == '"' || c == '\\' {
+			buf, _ = appendJSONReflect(buf, s)
+			return buf
+		}
+	}
+
+	buf = append(buf, '"')
+	buf = append(buf, s...)
+	return append(buf, '"')
+}
+
+// appendJSONReflect appends v encoded using encoding/json without HTML
+// escaping.
+func appendJSONReflect(buf []byte, v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(v)
+	if err != nil {
+		return buf, err
+	}
+
+	// Encode terminates the value with a newline.
+	return append(buf, bytes.TrimSuffix(b.Bytes(), []byte("\n"))...), nil
+}
+
+func safeString(str fmt.Stringer) (s string) {
+	defer func() {
+		if panicVal := recover(); panicVal != nil {
+			if v := reflect.ValueOf(str); v.Kind() == reflect.Ptr && v.IsNil() {
+				s = "NULL"
+			} else {
+				s = fmt.Sprintf("PANIC in String method: %v", panicVal)
+			}
+		}
+	}()
+	s = str.String()
+	return
+}
+
+func safeError(err error) (s interface{}) {
+	defer func() {
+		if panicVal := recover(); panicVal != nil {
+			if v := reflect.ValueOf(err); v.Kind() == reflect.Ptr && v.IsNil() {
+				s = nil
+			} else {
+				s = fmt.Sprintf("PANIC in Error method: %v", panicVal)
+			}
+		}
+	}()
+	s = err.Error()
+	return
+}
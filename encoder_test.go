@@ -0,0 +1,233 @@
+package micrologger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	kitlog "github.com/go-kit/log"
+	"github.com/google/go-cmp/cmp"
+)
+
+type testStringer struct {
+	s string
+}
+
+func (s *testStringer) String() string {
+	return s.s
+}
+
+type testErrorValue struct{}
+
+func (e *testErrorValue) Error() string {
+	return "test error value"
+}
+
+func Test_jsonLogger(t *testing.T) {
+	var nilStringer *testStringer
+	var nilError *testErrorValue
+
+	testCases := []struct {
+		name    string
+		keyVals []interface{}
+	}{
+		{
+			name:    "case 0: simple call with a key and a value",
+			keyVals: []interface{}{"foo", "bar", "caller", "logger_test.go:109", "time", "2019-10-08T20:04:13.490819+00:00"},
+		},
+		{
+			name:    "case 1: uneven number of keys",
+			keyVals: []interface{}{"foo", "bar", "baz"},
+		},
+		{
+			name:    "case 2: later keys win",
+			keyVals: []interface{}{"foo", "bar", "foo", "baz", "a", 1},
+		},
+		{
+			name: "case 3: escaped strings",
+			keyVals: []interface{}{
+				"quote", `"quoted" \ back`,
+				"control", "line\nbreak\ttab\x01\b\f",
+				"html", "<a href=\"x\">&</a>",
+				"unicode", "grüße    ",
+				"invalid", "\xff\xfe",
+				"key\n", "value",
+			},
+		},
+		{
+			name: "case 4: numbers and booleans",
+			keyVals: []interface{}{
+				"int", 1, "int8", int8(-8), "int16", int16(16), "int32", int32(-32), "int64", int64(math.MaxInt64),
+				"uint", uint(1), "uint8", uint8(8), "uint16", uint16(16), "uint32", uint32(32), "uint64", uint64(math.MaxUint64),
+				"float32", float32(1.1), "float64", 3.14, "small", 1e-7, "large", 1e21, "zero", 0.0, "negative", -2.5e-9,
+				"true", true, "false", false, "nil", nil,
+			},
+		},
+		{
+			name: "case 5: errors and stringers",
+			keyVals: []interface{}{
+				"error", errors.New("test error"),
+				"microerror", microerror.Mask(invalidConfigError),
+				"stringer", &testStringer{s: "test stringer"},
+				"nilStringer", nilStringer,
+				"nilError", nilError,
+				"duration", 1500 * time.Millisecond,
+				"ip", net.ParseIP("127.0.0.1"),
+				"time", time.Date(2019, 10, 8, 20, 4, 13, 0, time.UTC),
+			},
+		},
+		{
+			name: "case 6: nested values",
+			keyVals: []interface{}{
+				"stack", map[string]interface{}{
+					"kind":  "unknown",
+					"stack": []interface{}{map[string]interface{}{"file": "logger.go", "line": 12}, map[string]interface{}{"collapsed": 3}},
+				},
+				"nested", map[string]interface{}{"b": []interface{}{1, "x", nil}, "a": map[string]interface{}{"error": errors.New("nested")}},
+				"nilMap", map[string]interface{}(nil),
+				"nilSlice", []interface{}(nil),
+				"strings", []string{"a", "b"},
+				"struct", struct{ A string }{A: "a"},
+				"bytes", []byte("bytes"),
+			},
+		},
+		{
+			name:    "case 7: non-string keys",
+			keyVals: []interface{}{1, "one", &testStringer{s: "stringer"}, "two", nil, "three"},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			expected := &bytes.Buffer{}
+			expectedErr := kitlog.NewJSONLogger(expected).Log(tc.keyVals...)
+
+			w := &bytes.Buffer{}
+			err := newJSONLogger(w, nil).Log(tc.keyVals...)
+
+			if fmt.Sprint(err) != fmt.Sprint(expectedErr) {
+				t.Fatalf("err = %v, want %v", err, expectedErr)
+			}
+			if !cmp.Equal(w.String(), expected.String()) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(expected.String(), w.String()))
+			}
+		})
+	}
+}
+
+func Test_jsonLogger_logKeyVals(t *testing.T) {
+	testCases := []struct {
+		name    string
+		keyVals []interface{}
+		fields  []Field
+	}{
+		{
+			name:    "case 0: simple call with fields",
+			keyVals: []interface{}{"caller", "logger_test.go:109", "level", "debug", "message", "test", "foo", "bar"},
+			fields:  []Field{String("resource", "endpoint"), Int("count", 3), Duration("duration", time.Second)},
+		},
+		{
+			name:    "case 1: non-string values of held back keys",
+			keyVals: []interface{}{"caller", 1, "level", "debug", "level", 2, "message", "test", "caller", "logger_test.go:109"},
+		},
+		{
+			name:    "case 2: empty values of held back keys",
+			keyVals: []interface{}{"level", 1, "level", "", "message", ""},
+		},
+		{
+			name:    "case 3: fields overriding keyvals",
+			keyVals: []interface{}{"foo", "bar", "baz"},
+			fields:  []Field{String("foo", "baz"), Object("caller", 1), Err("error", errors.New("test error"))},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			expected := &bytes.Buffer{}
+			r := newRecordFields(context.Background(), time.Time{}, tc.keyVals, tc.fields, nil)
+			err := newJSONLogger(expected, nil).LogRecord(r)
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			w := &bytes.Buffer{}
+			err = newJSONLogger(w, nil).logKeyVals(tc.keyVals, tc.fields)
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			if !cmp.Equal(w.String(), expected.String()) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(expected.String(), w.String()))
+			}
+		})
+	}
+}
+
+func Test_jsonLogger_unsupportedValue(t *testing.T) {
+	w := &bytes.Buffer{}
+
+	err := newJSONLogger(w, nil).Log("nan", math.NaN())
+	if err == nil {
+		t.Fatalf("err = %v, want error", err)
+	}
+	if w.Len() != 0 {
+		t.Fatalf("output = %#q, want %#q", w.String(), "")
+	}
+}
+
+var benchmarkKeyVals = []interface{}{
+	"caller", "github.com/giantswarm/micrologger/logger_test.go:109",
+	"time", "2019-10-08T20:04:13.490819+00:00",
+	"level", "debug",
+	"message", "reconciling resource",
+	"controller", "cluster",
+	"resource", "endpoint",
+	"count", 3,
+	"duration", "1.5s",
+}
+
+func Benchmark_jsonLogger(b *testing.B) {
+	loggers := []struct {
+		name   string
+		logger kitlog.Logger
+	}{
+		{
+			name:   "kitlog",
+			logger: kitlog.NewJSONLogger(io.Discard),
+		},
+		{
+			name:   "stream",
+			logger: newJSONLogger(io.Discard, nil),
+		},
+	}
+
+	for _, l := range loggers {
+		b.Run(l.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = l.logger.Log(benchmarkKeyVals...)
+			}
+		})
+	}
+}
+
+func Benchmark_MicroLogger_Debug(b *testing.B) {
+	logger, err := New(Config{IOWriter: io.Discard})
+	if err != nil {
+		b.Fatalf("err = %v, want %v", err, nil)
+	}
+	l := logger.With("controller", "cluster", "resource", "endpoint")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Debug(context.Background(), "reconciling resource")
+	}
+}
@@ -12,3 +12,39 @@ var invalidConfigError = &microerror.Error{
 func IsInvalidConfig(err error) bool {
 	return microerror.Cause(err) == invalidConfigError
 }
+
+var invalidLevelError = &microerror.Error{
+	Kind: "invalidLevelError",
+}
+
+// IsInvalidLevel asserts invalidLevelError.
+func IsInvalidLevel(err error) bool {
+	return microerror.Cause(err) == invalidLevelError
+}
+
+var invalidKeyValsError = &microerror.Error{
+	Kind: "invalidKeyValsError",
+}
+
+// IsInvalidKeyVals asserts invalidKeyValsError.
+func IsInvalidKeyVals(err error) bool {
+	return microerror.Cause(err) == invalidKeyValsError
+}
+
+var encodeFailedError = &microerror.Error{
+	Kind: "encodeFailedError",
+}
+
+// IsEncodeFailed asserts encodeFailedError.
+func IsEncodeFailed(err error) bool {
+	return microerror.Cause(err) == encodeFailedError
+}
+
+var writeFailedError = &microerror.Error{
+	Kind: "writeFailedError",
+}
+
+// IsWriteFailed asserts writeFailedError.
+func IsWriteFailed(err error) bool {
+	return microerror.Cause(err) == writeFailedError
+}
@@ -0,0 +1,54 @@
+package micrologger
+
+import (
+	"errors"
+
+	"github.com/giantswarm/microerror"
+)
+
+// ErrorLevel classifies errors logged using Error and Errorf, so that expected
+// errors can be logged in lower levels than error. Errors match when their
+// microerror kind equals Kind or when Matcher returns true.
+type ErrorLevel struct {
+	// Kind is the microerror kind of matching errors, e.g. "notFoundError".
+	Kind string
+	// Matcher returns true for matching errors, e.g. IsNotFound.
+	Matcher func(err error) bool
+
+	// Level is the level matching errors are logged with, e.g. warning or
+	// info. Defaults to error.
+	Level string
+	// SkipStack omits the "stack" key for matching errors.
+	SkipStack bool
+}
+
+func (e ErrorLevel) matches(err error) bool {
+	if e.Matcher != nil && e.Matcher(err) {
+		return true
+	}
+
+	if e.Kind != "" {
+		var eerr *microerror.Error
+		if errors.As(err, &eerr) && eerr.Kind == e.Kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// classifyError returns the level and whether to skip the stack for the given
+// error according to the first matching ErrorLevel.
+func classifyError(errorLevels []ErrorLevel, err error) (string, bool) {
+	if err == nil {
+		return "error", false
+	}
+
+	for _, e := range errorLevels {
+		if e.matches(err) {
+			return e.Level, e.SkipStack
+		}
+	}
+
+	return "error", false
+}
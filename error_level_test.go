@@ -0,0 +1,147 @@
+package micrologger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/microerror"
+)
+
+var notFoundError = &microerror.Error{
+	Kind: "notFoundError",
+}
+
+func Test_MicroLogger_ErrorLevels(t *testing.T) {
+	errorLevels := []ErrorLevel{
+		{
+			Kind:      "notFoundError",
+			Level:     "info",
+			SkipStack: true,
+		},
+		{
+			Matcher: func(err error) bool {
+				return errors.Is(err, io.EOF)
+			},
+			Level: "warning",
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		err           error
+		expectedLevel string
+		expectedStack bool
+	}{
+		{
+			name:          "case 0: unclassified error",
+			err:           microerror.Maskf(testError, "foo"),
+			expectedLevel: "error",
+			expectedStack: true,
+		},
+		{
+			name:          "case 1: error matching kind",
+			err:           microerror.Maskf(notFoundError, "foo"),
+			expectedLevel: "info",
+			expectedStack: false,
+		},
+		{
+			name:          "case 2: error matching matcher",
+			err:           microerror.Mask(io.EOF),
+			expectedLevel: "warning",
+			expectedStack: true,
+		},
+		{
+			name:          "case 3: nil error",
+			err:           nil,
+			expectedLevel: "error",
+			expectedStack: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &bytes.Buffer{}
+
+			logger, err := New(Config{IOWriter: w, ErrorLevels: errorLevels})
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			// The activation logger must classify errors like its underlying
+			// logger does.
+			activationLogger, err := NewActivation(ActivationLoggerConfig{
+				Underlying: logger,
+				Activations: map[string]interface{}{
+					KeyLevel: "info",
+				},
+			})
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			for _, l := range []Logger{logger, activationLogger} {
+				w.Reset()
+				l.Error(context.Background(), tc.err, "test")
+
+				var entry map[string]interface{}
+				err = json.Unmarshal(w.Bytes(), &entry)
+				if err != nil {
+					t.Fatalf("err = %v, want %v", err, nil)
+				}
+
+				if entry["level"] != tc.expectedLevel {
+					t.Fatalf("level = %v, want %v", entry["level"], tc.expectedLevel)
+				}
+				if _, ok := entry["stack"]; ok != tc.expectedStack {
+					t.Fatalf("stack = %v, want %v", ok, tc.expectedStack)
+				}
+			}
+		})
+	}
+}
+
+func Test_ActivationLogger_ErrorLevels(t *testing.T) {
+	w := &bytes.Buffer{}
+
+	var logger Logger
+	{
+		underlying, err := New(Config{
+			IOWriter: w,
+			ErrorLevels: []ErrorLevel{
+				{Kind: "notFoundError", Level: "info"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("err = %v, want %v", err, nil)
+		}
+
+		logger, err = NewActivation(ActivationLoggerConfig{
+			Underlying: underlying,
+			Activations: map[string]interface{}{
+				KeyLevel: "error",
+			},
+		})
+		if err != nil {
+			t.Fatalf("err = %v, want %v", err, nil)
+		}
+	}
+
+	// Expected errors logged in info level must not be activated.
+	logger.Error(context.Background(), microerror.Maskf(notFoundError, "foo"), "test")
+
+	if w.Len() != 0 {
+		t.Fatalf("output = %#q, want empty", w.String())
+	}
+}
+
+func Test_MicroLogger_ErrorLevels_invalid(t *testing.T) {
+	_, err := New(Config{ErrorLevels: []ErrorLevel{{Level: "info"}}})
+	if !IsInvalidConfig(err) {
+		t.Fatalf("err = %v, want %v", err, invalidConfigError)
+	}
+}
@@ -0,0 +1,50 @@
+package micrologger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func Test_MicroLogger_Fatal(t *testing.T) {
+	w := &bytes.Buffer{}
+	b := bufio.NewWriter(w)
+
+	var exitCode int
+	var written []byte
+
+	c := Config{
+		IOWriter: b,
+		Exit: func(code int) {
+			exitCode = code
+			written = append(written, w.Bytes()...)
+		},
+	}
+
+	logger, err := New(c)
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	logger.Fatalf(context.Background(), errors.New("test"), "failed %s", "badly")
+
+	if exitCode != 1 {
+		t.Fatalf("exit code = %d, want %d", exitCode, 1)
+	}
+
+	var entry map[string]interface{}
+	err = json.Unmarshal(written, &entry)
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	if entry["level"] != "fatal" {
+		t.Fatalf("level = %v, want %v", entry["level"], "fatal")
+	}
+	if entry["message"] != "failed badly" {
+		t.Fatalf("message = %v, want %v", entry["message"], "failed badly")
+	}
+}
@@ -0,0 +1,114 @@
+package micrologger
+
+import (
+	"time"
+)
+
+type fieldKind uint8
+
+const (
+	fieldString fieldKind = iota
+	fieldInt
+	fieldDuration
+	fieldError
+	fieldObject
+)
+
+// Field is a typed key-value pair written using LogFields. Fields are created
+// using String, Int, Duration, Err and Object. They are written exactly like
+// the equivalent key-value pairs passed to Log.
+type Field struct {
+	Key string
+
+	kind fieldKind
+	str  string
+	num  int64
+	obj  interface{}
+}
+
+// String returns a Field writing the given string.
+func String(key string, value string) Field {
+	return Field{Key: key, kind: fieldString, str: value}
+}
+
+// Int returns a Field writing the given integer.
+func Int(key string, value int) Field {
+	return Field{Key: key, kind: fieldInt, num: int64(value)}
+}
+
+// Duration returns a Field writing the given duration in its string form, e.g.
+// "1.5s".
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, kind: fieldDuration, num: int64(value)}
+}
+
+// Err returns a Field writing the given error as object, just like error
+// values passed to Log. A nil error is written as null.
+func Err(key string, err error) Field {
+	return Field{Key: key, kind: fieldError, obj: err}
+}
+
+// Object returns a Field writing the given value, just like values passed to
+// Log, including Lazy values.
+func Object(key string, value interface{}) Field {
+	return Field{Key: key, kind: fieldObject, obj: value}
+}
+
+// Value returns the value written for the Field.
+func (f Field) Value() interface{} {
+	switch f.kind {
+	case fieldString:
+		return f.str
+	case fieldInt:
+		return f.num
+	case fieldDuration:
+		return time.Duration(f.num).String()
+	default:
+		return f.obj
+	}
+}
+
+// fieldKeyVals returns the key-value pairs of a record of the given level,
+// message and fields.
+func fieldKeyVals(level string, message string, fields []Field) []interface{} {
+	keyVals := make([]interface{}, 0, 4+2*len(fields))
+	keyVals = append(keyVals, KeyLevel, level, "message", message)
+	for _, f := range fields {
+		keyVals = append(keyVals, f.Key, f.Value())
+	}
+
+	return keyVals
+}
+
+// processFields prepares the values of fields like processKeyVals does for
+// key-value pairs. The given fields are not mutated.
+func (f stackFormatter) processFields(fields []Field) []Field {
+	var fieldsCopy []Field
+
+	for i, field := range fields {
+		// Errors are rendered as objects and the "stack" key is rendered
+		// from the JSON of microerror. Other fields are written as they are.
+		switch {
+		case field.kind == fieldError, field.Key == "stack":
+		case field.kind == fieldObject:
+			if _, ok := field.obj.(error); !ok {
+				continue
+			}
+		default:
+			continue
+		}
+
+		kvs := f.processKeyVals([]interface{}{field.Key, field.Value()})
+
+		if fieldsCopy == nil {
+			fieldsCopy = append([]Field{}, fields...)
+		}
+		fieldsCopy[i] = Object(field.Key, kvs[1])
+	}
+
+	if fieldsCopy == nil {
+		return fields
+	}
+
+	return fieldsCopy
+}
@@ -0,0 +1,127 @@
+package micrologger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_MicroLogger_LogFields(t *testing.T) {
+	testCases := []struct {
+		name    string
+		fields  []Field
+		keyVals []interface{}
+	}{
+		{
+			name:    "case 0: no fields",
+			keyVals: []interface{}{},
+		},
+		{
+			name: "case 1: common types",
+			fields: []Field{
+				String("resource", "endpoint"),
+				Int("count", 3),
+				Duration("duration", 1500*time.Millisecond),
+			},
+			keyVals: []interface{}{
+				"resource", "endpoint",
+				"count", 3,
+				"duration", "1.5s",
+			},
+		},
+		{
+			name: "case 2: errors and objects",
+			fields: []Field{
+				Err("error", errors.New("test error")),
+				Err("nil", nil),
+				Object("object", map[string]interface{}{"foo": "bar"}),
+			},
+			keyVals: []interface{}{
+				"error", errors.New("test error"),
+				"nil", nil,
+				"object", map[string]interface{}{"foo": "bar"},
+			},
+		},
+		{
+			name: "case 3: lazy values and stacks",
+			fields: []Field{
+				Object("lazy", Lazy(func() interface{} { return "computed" })),
+				String("stack", `{"kind":"unknown","annotation":"test"}`),
+			},
+			keyVals: []interface{}{
+				"lazy", Lazy(func() interface{} { return "computed" }),
+				"stack", `{"kind":"unknown","annotation":"test"}`,
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			config := Config{
+				Caller:             func() interface{} { return "--REPLACED--" },
+				TimestampFormatter: func() interface{} { return "2019-10-08T20:04:13.490819+00:00" },
+			}
+
+			w := &bytes.Buffer{}
+			config.IOWriter = w
+			logger, err := New(config)
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			expectedW := &bytes.Buffer{}
+			config.IOWriter = expectedW
+			expectedLogger, err := New(config)
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			ctx := newRequestContext(context.Background(), "1")
+
+			logger.LogFields(ctx, "info", "test", tc.fields...)
+			expectedLogger.LogCtx(ctx, append([]interface{}{"level", "info", "message", "test"}, tc.keyVals...)...)
+
+			if !cmp.Equal(w.String(), expectedW.String()) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(expectedW.String(), w.String()))
+			}
+		})
+	}
+}
+
+func Benchmark_MicroLogger_LogFields(b *testing.B) {
+	logger, err := New(Config{IOWriter: io.Discard})
+	if err != nil {
+		b.Fatalf("err = %v, want %v", err, nil)
+	}
+	ctx := newRequestContext(context.Background(), "1")
+
+	b.Run("LogCtx", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.LogCtx(ctx,
+				"level", "info",
+				"message", "reconciling resource",
+				"resource", "endpoint",
+				"count", 1000+i,
+				"duration", (1500 * time.Millisecond).String(),
+			)
+		}
+	})
+
+	b.Run("LogFields", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.LogFields(ctx, "info", "reconciling resource",
+				String("resource", "endpoint"),
+				Int("count", 1000+i),
+				Duration("duration", 1500*time.Millisecond),
+			)
+		}
+	})
+}
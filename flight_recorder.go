@@ -0,0 +1,138 @@
+package micrologger
+
+import (
+	"context"
+	"sync"
+
+	"github.com/giantswarm/micrologger/loggermeta"
+)
+
+const (
+	KeyBuffered = "buffered"
+)
+
+// flightRecorderScopes is the maximum number of scopes a flight recorder keeps
+// track of. When a new scope exceeds this limit the oldest scope is discarded
+// together with its buffered records.
+const flightRecorderScopes = 1024
+
+type flightRecord struct {
+	ctx     context.Context
+	keyVals []interface{}
+}
+
+type flightRing struct {
+	records []flightRecord
+	next    int
+}
+
+func newFlightRing(size int) *flightRing {
+	return &flightRing{
+		records: make([]flightRecord, 0, size),
+	}
+}
+
+func (r *flightRing) add(record flightRecord) {
+	if len(r.records) < cap(r.records) {
+		r.records = append(r.records, record)
+		return
+	}
+
+	r.records[r.next] = record
+	r.next = (r.next + 1) % len(r.records)
+}
+
+func (r *flightRing) all() []flightRecord {
+	var records []flightRecord
+	records = append(records, r.records[r.next:]...)
+	records = append(records, r.records[:r.next]...)
+	return records
+}
+
+// flightRecorder buffers records suppressed by a logger in bounded rings, one
+// per scope. A scope is either the logger itself or the value stored under
+// the configured loggermeta key, e.g. a request ID.
+type flightRecorder struct {
+	key  string
+	size int
+
+	mutex  sync.Mutex
+	order  []string
+	scopes map[string]*flightRing
+}
+
+func newFlightRecorder(key string, size int) *flightRecorder {
+	return &flightRecorder{
+		key:  key,
+		size: size,
+
+		scopes: map[string]*flightRing{},
+	}
+}
+
+// record buffers the given keyVals in the scope of ctx. The given origin
+// keyVals, i.e. the time and caller of the original logging call, are
+// preserved, so that they override those of the call dumping the record.
+func (f *flightRecorder) record(ctx context.Context, keyVals []interface{}, originKeyVals []interface{}) {
+	var kvs []interface{}
+	{
+		kvs = append(kvs, keyVals...)
+		kvs = append(kvs, originKeyVals...)
+	}
+
+	scope := f.scope(ctx)
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	r, ok := f.scopes[scope]
+	if !ok {
+		if len(f.order) >= flightRecorderScopes {
+			delete(f.scopes, f.order[0])
+			f.order = f.order[1:]
+		}
+
+		r = newFlightRing(f.size)
+		f.order = append(f.order, scope)
+		f.scopes[scope] = r
+	}
+
+	r.add(flightRecord{ctx: ctx, keyVals: kvs})
+}
+
+// flush removes the scope of ctx and returns its buffered records, oldest
+// first.
+func (f *flightRecorder) flush(ctx context.Context) []flightRecord {
+	scope := f.scope(ctx)
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	r, ok := f.scopes[scope]
+	if !ok {
+		return nil
+	}
+
+	delete(f.scopes, scope)
+	for i, s := range f.order {
+		if s == scope {
+			f.order = append(f.order[:i], f.order[i+1:]...)
+			break
+		}
+	}
+
+	return r.all()
+}
+
+func (f *flightRecorder) scope(ctx context.Context) string {
+	if ctx == nil || f.key == "" {
+		return ""
+	}
+
+	meta, ok := loggermeta.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	return meta.KeyVals[f.key]
+}
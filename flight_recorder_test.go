@@ -0,0 +1,274 @@
+package micrologger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/giantswarm/micrologger/loggermeta"
+)
+
+func Test_ActivationLogger_FlightRecorder(t *testing.T) {
+	testCases := []struct {
+		name     string
+		level    string
+		key      string
+		size     int
+		log      func(ctx context.Context, l Logger)
+		expected []string
+	}{
+		{
+			name: "case 0: buffered records are discarded without error",
+			size: 2,
+			log: func(ctx context.Context, l Logger) {
+				l.Debug(ctx, "one")
+				l.Debug(ctx, "two")
+			},
+			expected: nil,
+		},
+		{
+			name: "case 1: buffered records are dumped before the error",
+			size: 2,
+			log: func(ctx context.Context, l Logger) {
+				l.Debug(ctx, "one")
+				l.Debug(ctx, "two")
+				l.Debug(ctx, "three")
+				l.Error(ctx, errors.New("test"), "four")
+				l.Debug(ctx, "five")
+			},
+			expected: []string{
+				"two buffered",
+				"three buffered",
+				"four",
+			},
+		},
+		{
+			name: "case 2: records logged without context are dumped too",
+			size: 2,
+			log: func(ctx context.Context, l Logger) {
+				l.Log("level", "debug", "message", "one")
+				l.Log("level", "error", "message", "two")
+			},
+			expected: []string{
+				"one buffered",
+				"two",
+			},
+		},
+		{
+			name: "case 3: records are scoped by loggermeta key",
+			key:  "request_id",
+			size: 3,
+			log: func(ctx context.Context, l Logger) {
+				ctx1 := newRequestContext(ctx, "1")
+				ctx2 := newRequestContext(ctx, "2")
+
+				l.Debug(ctx1, "one")
+				l.Debug(ctx2, "two")
+				l.Debug(ctx1, "three")
+				l.Error(ctx1, nil, "four")
+				l.Debug(ctx2, "five")
+			},
+			expected: []string{
+				"one buffered",
+				"three buffered",
+				"four",
+			},
+		},
+		{
+			name:  "case 4: buffered records bypass the level of the underlying logger",
+			level: "error",
+			size:  2,
+			log: func(ctx context.Context, l Logger) {
+				l.Debug(ctx, "one")
+				l.Log("level", "info", "message", "two")
+				l.Error(ctx, errors.New("test"), "three")
+			},
+			expected: []string{
+				"one buffered",
+				"two buffered",
+				"three",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &bytes.Buffer{}
+
+			var logger Logger
+			{
+				underlying, err := New(Config{IOWriter: w, Level: tc.level})
+				if err != nil {
+					t.Fatalf("err = %v, want %v", err, nil)
+				}
+
+				c := ActivationLoggerConfig{
+					Underlying: underlying,
+
+					Activations: map[string]interface{}{
+						KeyLevel: "error",
+					},
+					FlightRecorderKey:  tc.key,
+					FlightRecorderSize: tc.size,
+				}
+
+				logger, err = NewActivation(c)
+				if err != nil {
+					t.Fatalf("err = %v, want %v", err, nil)
+				}
+			}
+
+			tc.log(context.Background(), logger)
+
+			var actual []string
+			for _, line := range strings.Split(strings.TrimSpace(w.String()), "\n") {
+				if line == "" {
+					continue
+				}
+
+				var m map[string]interface{}
+				err := json.Unmarshal([]byte(line), &m)
+				if err != nil {
+					t.Fatalf("err = %v, want %v", err, nil)
+				}
+
+				s := m["message"].(string)
+				if m[KeyBuffered] == true {
+					s += " buffered"
+				}
+				actual = append(actual, s)
+			}
+
+			if !cmp.Equal(actual, tc.expected) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expected, actual))
+			}
+		})
+	}
+}
+
+func newRequestContext(ctx context.Context, id string) context.Context {
+	meta := loggermeta.New()
+	meta.KeyVals["request_id"] = id
+
+	return loggermeta.NewContext(ctx, meta)
+}
+
+func Test_ActivationLogger_FlightRecorder_Caller(t *testing.T) {
+	w := &bytes.Buffer{}
+
+	var logger Logger
+	{
+		underlying, err := New(Config{
+			IOWriter:     w,
+			CallerFormat: CallerFormat{Split: true},
+		})
+		if err != nil {
+			t.Fatalf("err = %v, want %v", err, nil)
+		}
+
+		c := ActivationLoggerConfig{
+			Underlying: underlying,
+
+			Activations: map[string]interface{}{
+				KeyLevel: "error",
+			},
+			FlightRecorderSize: 1,
+		}
+
+		logger, err = NewActivation(c)
+		if err != nil {
+			t.Fatalf("err = %v, want %v", err, nil)
+		}
+	}
+
+	ctx := context.Background()
+	logger.Debug(ctx, "one")
+	expected := line() - 1
+	logger.Error(ctx, errors.New("test"), "two")
+
+	var m map[string]interface{}
+	err := json.Unmarshal([]byte(strings.Split(w.String(), "\n")[0]), &m)
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	if m[KeyBuffered] != true {
+		t.Fatalf("buffered = %v, want %v", m[KeyBuffered], true)
+	}
+	if _, ok := m[KeyCaller]; ok {
+		t.Fatalf("caller = %v, want none", m[KeyCaller])
+	}
+	if m[KeyCallerLine] != float64(expected) {
+		t.Fatalf("caller_line = %v, want %v", m[KeyCallerLine], expected)
+	}
+	if !strings.HasSuffix(m[KeyCallerFile].(string), "flight_recorder_test.go") {
+		t.Fatalf("caller_file = %v, want %v", m[KeyCallerFile], "flight_recorder_test.go")
+	}
+}
+
+func Test_ActivationLogger_FlightRecorder_Time(t *testing.T) {
+	w := &bytes.Buffer{}
+
+	var logger Logger
+	{
+		var n int
+		underlying, err := New(Config{
+			IOWriter: w,
+			TimestampFormatter: func() interface{} {
+				n++
+				return strconv.Itoa(n)
+			},
+		})
+		if err != nil {
+			t.Fatalf("err = %v, want %v", err, nil)
+		}
+
+		c := ActivationLoggerConfig{
+			Underlying: underlying,
+
+			Activations: map[string]interface{}{
+				KeyLevel: "error",
+			},
+			FlightRecorderSize: 2,
+		}
+
+		logger, err = NewActivation(c)
+		if err != nil {
+			t.Fatalf("err = %v, want %v", err, nil)
+		}
+	}
+
+	ctx := context.Background()
+	logger.Debug(ctx, "one")
+	logger.Log("level", "debug", "message", "two")
+	logger.Error(ctx, errors.New("test"), "three")
+
+	var actual []string
+	for _, line := range strings.Split(strings.TrimSpace(w.String()), "\n") {
+		var m map[string]interface{}
+		err := json.Unmarshal([]byte(line), &m)
+		if err != nil {
+			t.Fatalf("err = %v, want %v", err, nil)
+		}
+
+		actual = append(actual, m["message"].(string)+" "+m["time"].(string))
+	}
+
+	// Buffered records keep the time of their original logging call. The
+	// times formatted when dumping them, 3 and 4, are overridden.
+	expected := []string{
+		"one 1",
+		"two 2",
+		"three 5",
+	}
+
+	if !cmp.Equal(actual, expected) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(expected, actual))
+	}
+}
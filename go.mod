@@ -8,7 +8,6 @@ require (
 	github.com/giantswarm/microerror v0.4.1
 	github.com/go-kit/log v0.2.1
 	github.com/go-logr/logr v1.4.4
-	github.com/go-stack/stack v1.8.1
 	github.com/google/go-cmp v0.7.0
 )
 
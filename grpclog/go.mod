@@ -0,0 +1,26 @@
+module github.com/giantswarm/micrologger/grpclog
+
+go 1.21
+
+toolchain go1.26.6
+
+require (
+	github.com/giantswarm/microerror v0.4.1
+	github.com/giantswarm/micrologger v1.1.2
+	google.golang.org/grpc v1.67.1
+)
+
+require (
+	github.com/go-kit/log v0.2.1 // indirect
+	github.com/go-logfmt/logfmt v0.6.0 // indirect
+	github.com/go-logr/logr v1.4.4 // indirect
+	golang.org/x/net v0.28.0 // indirect
+	golang.org/x/sys v0.24.0 // indirect
+	golang.org/x/text v0.17.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240814211410-ddb44dafa142 // indirect
+	google.golang.org/protobuf v1.34.2 // indirect
+)
+
+// The interceptors depend on APIs of the micrologger module in this
+// repository which are not released yet.
+replace github.com/giantswarm/micrologger => ../
@@ -0,0 +1,194 @@
+package grpclog
+
+import (
+	"context"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/micrologger/loggermeta"
+)
+
+type Config struct {
+	Logger micrologger.Logger
+
+	// Level is the level calls are logged with. Defaults to info.
+	Level string
+	// Metadata maps loggermeta keys to the gRPC metadata keys they are
+	// extracted from on incoming calls and propagated as on outgoing calls.
+	// Defaults to the request ID as x-request-id.
+	Metadata map[string]string
+}
+
+// Interceptors provides gRPC interceptors for servers and clients. Server
+// interceptors extract the configured metadata into loggermeta, client
+// interceptors propagate loggermeta as metadata. Both log every call with its
+// method, code, duration and peer. Client streams are logged once they are
+// opened, when their peer is not known yet. Server interceptors seed the
+// method into loggermeta under KeyMethod, client interceptors log it under
+// KeyClientMethod, so that calls made while handling a call keep both.
+type Interceptors struct {
+	logger   micrologger.Logger
+	level    string
+	metadata map[string]string
+}
+
+func New(config Config) (*Interceptors, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Level == "" {
+		config.Level = "info"
+	}
+	if config.Metadata == nil {
+		config.Metadata = map[string]string{
+			KeyRequestID: MetadataRequestID,
+		}
+	}
+
+	i := &Interceptors{
+		logger:   config.Logger,
+		level:    config.Level,
+		metadata: config.Metadata,
+	}
+
+	return i, nil
+}
+
+func (i *Interceptors) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		ctx = i.incomingContext(ctx, info.FullMethod)
+
+		res, err := handler(ctx, req)
+		i.log(ctx, "handled grpc call", start, err)
+
+		return res, err
+	}
+}
+
+func (i *Interceptors) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx := i.incomingContext(ss.Context(), info.FullMethod)
+
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		i.log(ctx, "handled grpc stream", start, err)
+
+		return err
+	}
+}
+
+func (i *Interceptors) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		ctx = i.outgoingContext(ctx)
+
+		var p peer.Peer
+		opts = append(opts, grpc.Peer(&p))
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		i.log(peer.NewContext(ctx, &p), "sent grpc call", start, err, KeyClientMethod, method)
+
+		return err
+	}
+}
+
+func (i *Interceptors) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		ctx = i.outgoingContext(ctx)
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		i.log(ctx, "opened grpc stream", start, err, KeyClientMethod, method)
+
+		return cs, err
+	}
+}
+
+// incomingContext returns a new context.Context carrying a copy of the
+// loggermeta of ctx, if any, extended with the gRPC method and the configured
+// incoming metadata.
+func (i *Interceptors) incomingContext(ctx context.Context, method string) context.Context {
+	meta := loggermeta.New()
+	if m, ok := loggermeta.FromContext(ctx); ok {
+		for k, v := range m.KeyVals {
+			meta.KeyVals[k] = v
+		}
+	}
+
+	meta.KeyVals[KeyMethod] = method
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for k, mdKey := range i.metadata {
+		v := md.Get(mdKey)
+		if len(v) == 0 {
+			continue
+		}
+		meta.KeyVals[k] = v[0]
+	}
+
+	return loggermeta.NewContext(ctx, meta)
+}
+
+// outgoingContext returns a new context.Context with the configured loggermeta
+// values of ctx appended to the outgoing metadata.
+func (i *Interceptors) outgoingContext(ctx context.Context) context.Context {
+	meta, ok := loggermeta.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+
+	var kv []string
+	for k, mdKey := range i.metadata {
+		v, ok := meta.KeyVals[k]
+		if !ok || len(md.Get(mdKey)) != 0 {
+			continue
+		}
+		kv = append(kv, mdKey, v)
+	}
+
+	if len(kv) == 0 {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+func (i *Interceptors) log(ctx context.Context, message string, start time.Time, err error, keyVals ...interface{}) {
+	kvs := []interface{}{
+		"level", i.level,
+		"message", message,
+		KeyCode, status.Code(err).String(),
+		KeyDuration, time.Since(start).String(),
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		kvs = append(kvs, KeyPeer, p.Addr.String())
+	}
+	if err != nil {
+		kvs = append(kvs, "error", err)
+	}
+
+	i.logger.LogCtx(ctx, append(kvs, keyVals...)...)
+}
+
+// serverStream overrides the context.Context of the wrapped grpc.ServerStream
+// so that handlers see the seeded loggermeta.
+type serverStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
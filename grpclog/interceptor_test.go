@@ -0,0 +1,207 @@
+package grpclog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/micrologger/loggermeta"
+)
+
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+
+	requestIDs []string
+}
+
+func (s *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	s.requestIDs = append(s.requestIDs, requestIDFromContext(ctx))
+
+	if req.Service != "" {
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *healthServer) Watch(req *healthpb.HealthCheckRequest, ws healthpb.Health_WatchServer) error {
+	s.requestIDs = append(s.requestIDs, requestIDFromContext(ws.Context()))
+
+	return ws.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING})
+}
+
+func Test_Interceptors(t *testing.T) {
+	serverOutput := &bytes.Buffer{}
+	clientOutput := &bytes.Buffer{}
+
+	hs := &healthServer{}
+
+	var s *grpc.Server
+	var conn *grpc.ClientConn
+	{
+		serverInterceptors := newInterceptors(t, serverOutput)
+		clientInterceptors := newInterceptors(t, clientOutput)
+
+		lis := bufconn.Listen(1024 * 1024)
+
+		s = grpc.NewServer(
+			grpc.UnaryInterceptor(serverInterceptors.UnaryServerInterceptor()),
+			grpc.StreamInterceptor(serverInterceptors.StreamServerInterceptor()),
+		)
+		healthpb.RegisterHealthServer(s, hs)
+		go func() {
+			_ = s.Serve(lis)
+		}()
+
+		var err error
+		conn, err = grpc.NewClient(
+			"passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithUnaryInterceptor(clientInterceptors.UnaryClientInterceptor()),
+			grpc.WithStreamInterceptor(clientInterceptors.StreamClientInterceptor()),
+		)
+		if err != nil {
+			t.Fatalf("err = %v, want %v", err, nil)
+		}
+	}
+
+	var ctx context.Context
+	{
+		meta := loggermeta.New()
+		meta.KeyVals[KeyRequestID] = "foo"
+
+		ctx = loggermeta.NewContext(context.Background(), meta)
+	}
+
+	client := healthpb.NewHealthClient(conn)
+
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("code = %v, want %v", status.Code(err), codes.NotFound)
+	}
+	{
+		ws, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			t.Fatalf("err = %v, want %v", err, nil)
+		}
+		_, err = ws.Recv()
+		if err != nil {
+			t.Fatalf("err = %v, want %v", err, nil)
+		}
+	}
+
+	// Stop the server to make sure all server side logs are written.
+	conn.Close()
+	s.GracefulStop()
+
+	for i, id := range hs.requestIDs {
+		if id != "foo" {
+			t.Fatalf("request ID %d = %#q, want %#q", i, id, "foo")
+		}
+	}
+
+	// The peer of client streams is not known yet when they are opened.
+	checkEntries(t, clientOutput, KeyClientMethod, []string{"OK", "NotFound", "OK"}, []bool{true, true, false})
+	checkEntries(t, serverOutput, KeyMethod, []string{"OK", "NotFound", "OK"}, []bool{true, true, true})
+}
+
+func Test_Interceptors_NestedCall(t *testing.T) {
+	w := &bytes.Buffer{}
+	i := newInterceptors(t, w)
+
+	// The server interceptor seeds the method of the handled call into the
+	// loggermeta of the context the outgoing call is made with.
+	ctx := i.incomingContext(context.Background(), "/server.Service/Handle")
+
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return nil
+	}
+	err := i.UnaryClientInterceptor()(ctx, "/client.Service/Call", nil, nil, nil, invoker)
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	var entry map[string]interface{}
+	err = json.Unmarshal(w.Bytes(), &entry)
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	if entry[KeyClientMethod] != "/client.Service/Call" {
+		t.Fatalf("client method = %v, want %v", entry[KeyClientMethod], "/client.Service/Call")
+	}
+	if entry[KeyMethod] != "/server.Service/Handle" {
+		t.Fatalf("method = %v, want %v", entry[KeyMethod], "/server.Service/Handle")
+	}
+}
+
+func checkEntries(t *testing.T, w *bytes.Buffer, methodKey string, codes []string, peers []bool) {
+	t.Helper()
+
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	if len(lines) != len(codes) {
+		t.Fatalf("lines = %d, want %d", len(lines), len(codes))
+	}
+
+	for i, line := range lines {
+		var entry map[string]interface{}
+		err := json.Unmarshal([]byte(line), &entry)
+		if err != nil {
+			t.Fatalf("err = %v, want %v", err, nil)
+		}
+
+		if entry[KeyCode] != codes[i] {
+			t.Fatalf("code = %v, want %v", entry[KeyCode], codes[i])
+		}
+		if entry[KeyRequestID] != "foo" {
+			t.Fatalf("request ID = %v, want %v", entry[KeyRequestID], "foo")
+		}
+		if !strings.HasPrefix(entry[methodKey].(string), "/grpc.health.v1.Health/") {
+			t.Fatalf("method = %v, want health method", entry[methodKey])
+		}
+		if (entry[KeyPeer] != nil) != peers[i] {
+			t.Fatalf("peer = %v, want present %v", entry[KeyPeer], peers[i])
+		}
+	}
+}
+
+func newInterceptors(t *testing.T, w *bytes.Buffer) *Interceptors {
+	logger, err := micrologger.New(micrologger.Config{IOWriter: w})
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	i, err := New(Config{Logger: logger})
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	return i
+}
+
+func requestIDFromContext(ctx context.Context) string {
+	meta, ok := loggermeta.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	return meta.KeyVals[KeyRequestID]
+}
@@ -0,0 +1,18 @@
+// Package grpclog provides gRPC server and client interceptors which log
+// calls using micrologger and carry loggermeta values such as the request ID
+// across services.
+package grpclog
+
+const (
+	// MetadataRequestID is the gRPC metadata key carrying the request ID.
+	MetadataRequestID = "x-request-id"
+)
+
+const (
+	KeyClientMethod = "grpc_client_method"
+	KeyCode         = "grpc_code"
+	KeyDuration     = "duration"
+	KeyMethod       = "grpc_method"
+	KeyPeer         = "peer"
+	KeyRequestID    = "request_id"
+)
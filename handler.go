@@ -0,0 +1,96 @@
+package micrologger
+
+import (
+	"io"
+
+	"github.com/giantswarm/microerror"
+	kitlog "github.com/go-kit/log"
+)
+
+// Handler writes records of a MicroLogger, e.g. by encoding them to an
+// io.Writer or by passing them on to another logging library. Handle is
+// called concurrently. Returned errors are passed to Config.ErrorHandler.
+type Handler interface {
+	Handle(r *Record) error
+}
+
+// Flusher is implemented by handlers which buffer records, so that they are
+// written before the process exits using Fatal and when the output is
+// redirected using SetOutput. Flush is called concurrently with Handle.
+type Flusher interface {
+	Flush() error
+}
+
+type JSONHandlerConfig struct {
+	IOWriter io.Writer
+	// FallbackWriter receives records which could not be written to the
+	// IOWriter. Defaults to no fallback.
+	FallbackWriter io.Writer
+}
+
+// JSONHandler writes every record as single line JSON object with sorted keys.
+// Records which cannot be encoded are reported as encodeFailedError and
+// records which cannot be written as writeFailedError.
+type JSONHandler struct {
+	logger *jsonLogger
+}
+
+func NewJSONHandler(config JSONHandlerConfig) (*JSONHandler, error) {
+	if config.IOWriter == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.IOWriter must not be empty", config)
+	}
+
+	var fallbackWriter io.Writer
+	if config.FallbackWriter != nil {
+		fallbackWriter = newSyncWriter(config.FallbackWriter)
+	}
+
+	h := &JSONHandler{
+		logger: newJSONLogger(newSyncWriter(config.IOWriter), fallbackWriter),
+	}
+
+	return h, nil
+}
+
+func (h *JSONHandler) Handle(r *Record) error {
+	return h.logger.LogRecord(r)
+}
+
+// Flush flushes the IOWriter and the FallbackWriter in case they buffer
+// writes, e.g. *bufio.Writer, or syncs them in case they are regular files.
+// Failures are returned as writeFailedError.
+func (h *JSONHandler) Flush() error {
+	err := flush(h.logger.w)
+
+	var fallbackErr error
+	if h.logger.fallback != nil {
+		fallbackErr = flush(h.logger.fallback)
+	}
+
+	if err != nil && fallbackErr != nil {
+		return microerror.Maskf(writeFailedError, "flush: %s, fallback: %s", err.Error(), fallbackErr.Error())
+	} else if err != nil {
+		return microerror.Maskf(writeFailedError, "flush: %s", err.Error())
+	} else if fallbackErr != nil {
+		return microerror.Maskf(writeFailedError, "flush fallback: %s", fallbackErr.Error())
+	}
+
+	return nil
+}
+
+// KitlogHandler passes records on to a kitlog.Logger.
+type KitlogHandler struct {
+	logger kitlog.Logger
+}
+
+// NewKitlogHandler returns a Handler passing the key-value pairs of records
+// on to the given kitlog.Logger, e.g. one created using kitlog.NewLogfmtLogger.
+func NewKitlogHandler(logger kitlog.Logger) *KitlogHandler {
+	return &KitlogHandler{
+		logger: logger,
+	}
+}
+
+func (h *KitlogHandler) Handle(r *Record) error {
+	return h.logger.Log(r.KeyVals()...)
+}
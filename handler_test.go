@@ -0,0 +1,148 @@
+package micrologger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	kitlog "github.com/go-kit/log"
+	"github.com/google/go-cmp/cmp"
+)
+
+type testHandler struct {
+	mutex   sync.Mutex
+	records []*Record
+	flushes int
+}
+
+func (h *testHandler) Handle(r *Record) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.records = append(h.records, r)
+	return nil
+}
+
+func (h *testHandler) Flush() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.flushes++
+	return nil
+}
+
+func Test_MicroLogger_Handler(t *testing.T) {
+	h := &testHandler{}
+
+	logger, err := New(Config{
+		Handler:            h,
+		TimestampFormatter: func() interface{} { return "--REPLACED--" },
+	})
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	logger.With("foo", "bar").Debug(context.Background(), "test")
+	expectedCaller := fmt.Sprintf("/handler_test.go:%d", line()-1)
+
+	if len(h.records) != 1 {
+		t.Fatalf("records = %d, want %d", len(h.records), 1)
+	}
+	r := h.records[0]
+
+	if r.Time.IsZero() {
+		t.Fatalf("time = %v, want non-zero", r.Time)
+	}
+	if !strings.HasSuffix(r.Caller, expectedCaller) {
+		t.Fatalf("caller = %#q, want suffix %#q", r.Caller, expectedCaller)
+	}
+	if r.Level != "debug" {
+		t.Fatalf("level = %#q, want %#q", r.Level, "debug")
+	}
+	if r.Message != "test" {
+		t.Fatalf("message = %#q, want %#q", r.Message, "test")
+	}
+
+	var fields []interface{}
+	for _, f := range r.Fields {
+		fields = append(fields, f.Key, f.Value())
+	}
+	expected := []interface{}{"time", "--REPLACED--", "foo", "bar"}
+	if !cmp.Equal(fields, expected) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(expected, fields))
+	}
+}
+
+func Test_KitlogHandler(t *testing.T) {
+	w := &bytes.Buffer{}
+
+	logger, err := New(Config{
+		Caller:             func() interface{} { return "--REPLACED--" },
+		Handler:            NewKitlogHandler(kitlog.NewLogfmtLogger(w)),
+		TimestampFormatter: func() interface{} { return "--REPLACED--" },
+	})
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	logger.With("foo", "bar").Debug(context.Background(), "test")
+
+	expected := "caller=--REPLACED-- time=--REPLACED-- foo=bar level=debug message=test\n"
+	if w.String() != expected {
+		t.Fatalf("output = %#q, want %#q", w.String(), expected)
+	}
+}
+
+func Test_NewJSONHandler(t *testing.T) {
+	_, err := NewJSONHandler(JSONHandlerConfig{})
+	if !IsInvalidConfig(err) {
+		t.Fatalf("err = %v, want %v", err, invalidConfigError)
+	}
+
+	w := &bytes.Buffer{}
+	h, err := NewJSONHandler(JSONHandlerConfig{IOWriter: w})
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	err = h.Handle(&Record{Caller: "caller", Level: "info", Message: "test", Fields: []Field{Int("count", 1)}})
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	expected := `{"caller":"caller","count":1,"level":"info","message":"test"}` + "\n"
+	if w.String() != expected {
+		t.Fatalf("output = %#q, want %#q", w.String(), expected)
+	}
+}
+
+func Test_MicroLogger_Handler_Flush(t *testing.T) {
+	h := &testHandler{}
+
+	var exitCode int
+	logger, err := New(Config{
+		Handler: h,
+		Exit: func(code int) {
+			exitCode = code
+		},
+	})
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	logger.SetOutput(&bytes.Buffer{})
+	if h.flushes != 1 {
+		t.Fatalf("flushes = %d, want %d", h.flushes, 1)
+	}
+
+	logger.Fatal(context.Background(), nil, "test")
+	if h.flushes != 2 {
+		t.Fatalf("flushes = %d, want %d", h.flushes, 2)
+	}
+	if exitCode != 1 {
+		t.Fatalf("exit code = %d, want %d", exitCode, 1)
+	}
+}
@@ -0,0 +1,156 @@
+package micrologger
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var (
+	// packagePath is the import path of this package. Frames of its non
+	// test files are always skipped when resolving the caller.
+	packagePath = reflect.TypeOf(MicroLogger{}).PkgPath()
+
+	helperFuncs    sync.Map
+	helperPackages sync.Map
+	// helperCache caches the results of isHelper by function name. It is
+	// invalidated when helpers are registered.
+	helperCache sync.Map
+)
+
+func init() {
+	RegisterHelperPackage("github.com/go-kit/log")
+	RegisterHelperPackage("github.com/go-logr/logr")
+	RegisterHelperPackage("log/slog")
+
+	// Frames of the runtime, e.g. runtime.gopanic, are skipped so that
+	// records logged by Recover report the panicking function.
+	RegisterHelperPackage("runtime")
+}
+
+// Helper marks the calling function as logging helper, just like
+// testing.T.Helper does for tests. Frames of helper functions are skipped
+// when resolving the caller of logging calls, so that wrappers report the
+// call site of their callers.
+func Helper() {
+	pc, _, _, ok := runtime.Caller(1)
+	if !ok {
+		return
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return
+	}
+
+	if _, ok := helperFuncs.Load(fn.Name()); !ok {
+		helperFuncs.Store(fn.Name(), struct{}{})
+		helperCache.Delete(fn.Name())
+	}
+}
+
+// RegisterHelperPackage registers the package with the given import path as
+// logging helper package. Frames of all its functions are skipped when
+// resolving the caller of logging calls. Sub packages are not included. The
+// packages of go-kit/log, go-logr/logr, log/slog and the runtime are
+// registered by default.
+func RegisterHelperPackage(importPath string) {
+	helperPackages.Store(importPath, struct{}{})
+
+	helperCache.Range(func(k, _ interface{}) bool {
+		helperCache.Delete(k)
+		return true
+	})
+}
+
+// callersBatch is the number of program counters read at once when walking
+// the stack in findCaller. The caller of logging calls is usually found
+// within the first batch.
+const callersBatch = 8
+
+// findCaller returns the frame of the first caller which neither belongs to
+// this package nor to a registered helper, nor is skipped by the given
+// function. Then skip further frames are skipped. In case all frames are
+// skipped the outermost frame is returned.
+func findCaller(skip int, skipFunc func(f runtime.Frame) bool) runtime.Frame {
+	var pcs [callersBatch]uintptr
+
+	// depth is the number of frames walked, starting at the caller of
+	// findCaller. found is the depth of the first frame not skipped.
+	var last runtime.Frame
+	depth := 0
+	found := -1
+	for {
+		// Skip runtime.Callers and findCaller.
+		n := runtime.Callers(2+depth, pcs[:])
+		if n == 0 {
+			break
+		}
+
+		frames := runtime.CallersFrames(pcs[:n])
+		for {
+			f, more := frames.Next()
+
+			if found < 0 && !isHelper(f) && (skipFunc == nil || !skipFunc(f)) {
+				found = depth
+			}
+			if found >= 0 && depth == found+skip {
+				return f
+			}
+
+			last = f
+			depth++
+			if !more {
+				break
+			}
+		}
+
+		if n < len(pcs) {
+			break
+		}
+	}
+
+	return last
+}
+
+// isHelper returns whether the given frame belongs to this package or to a
+// registered helper. Results are cached per function.
+func isHelper(f runtime.Frame) bool {
+	if v, ok := helperCache.Load(f.Function); ok {
+		return v.(bool)
+	}
+
+	helper := isHelperFunc(f.Function, f.File)
+	helperCache.Store(f.Function, helper)
+
+	return helper
+}
+
+func isHelperFunc(function string, file string) bool {
+	if _, ok := helperFuncs.Load(function); ok {
+		return true
+	}
+
+	pkg := funcPackage(function)
+	if pkg == packagePath && !strings.HasSuffix(file, "_test.go") {
+		return true
+	}
+	if _, ok := helperPackages.Load(pkg); ok {
+		return true
+	}
+
+	return false
+}
+
+// funcPackage returns the import path of the package of the given fully
+// qualified function name, e.g. "github.com/go-kit/log" for
+// "github.com/go-kit/log.(*context).Log".
+func funcPackage(name string) string {
+	i := strings.LastIndex(name, "/")
+	j := strings.Index(name[i+1:], ".")
+	if j < 0 {
+		return name
+	}
+
+	return name[:i+1+j]
+}
@@ -0,0 +1,256 @@
+package micrologger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+)
+
+func Test_MicroLogger_caller(t *testing.T) {
+	testCases := []struct {
+		name string
+		log  func(l Logger) int
+	}{
+		{
+			name: "case 0: Debugf",
+			log: func(l Logger) int {
+				l.Debugf(context.Background(), "test %d", 0)
+				return line()
+			},
+		},
+		{
+			name: "case 1: Errorf",
+			log: func(l Logger) int {
+				l.Errorf(context.Background(), nil, "test %d", 1)
+				return line()
+			},
+		},
+		{
+			name: "case 2: helper function",
+			log: func(l Logger) int {
+				logHelper(l, "test")
+				return line()
+			},
+		},
+		{
+			name: "case 3: activation logger",
+			log: func(l Logger) int {
+				a, err := NewActivation(ActivationLoggerConfig{
+					Underlying:  l,
+					Activations: map[string]interface{}{KeyLevel: "debug"},
+				})
+				if err != nil {
+					t.Fatalf("err = %v, want %v", err, nil)
+				}
+
+				a.Debugf(context.Background(), "test %d", 3)
+				return line()
+			},
+		},
+		{
+			name: "case 4: increased caller depth",
+			log: func(l Logger) int {
+				logWrapper(l.WithIncreasedCallerDepth(), "test")
+				return line()
+			},
+		},
+		{
+			name: "case 5: nested caller skips",
+			log: func(l Logger) int {
+				logNestedWrapper(l.(*MicroLogger).WithCallerSkip(1), "test")
+				return line()
+			},
+		},
+		{
+			name: "case 6: logr call depth",
+			log: func(l Logger) int {
+				logrWrapper(logr.New(l.(*MicroLogger).AsSink(1)), "test")
+				return line()
+			},
+		},
+		{
+			name: "case 7: logr call stack helper",
+			log: func(l Logger) int {
+				logrHelper(logr.New(l.(*MicroLogger).AsSink(1)), "test")
+				return line()
+			},
+		},
+		{
+			name: "case 8: helper frames beyond the first batch",
+			log: func(l Logger) int {
+				logRecursiveHelper(l, 2*callersBatch, "test")
+				return line()
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &bytes.Buffer{}
+
+			logger, err := New(Config{IOWriter: w})
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			// The logging call is on the line before the one returned.
+			expected := fmt.Sprintf("/helper_test.go:%d", tc.log(logger)-1)
+
+			var entry map[string]interface{}
+			err = json.Unmarshal(w.Bytes(), &entry)
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			caller, _ := entry[KeyCaller].(string)
+			if !strings.HasSuffix(caller, expected) {
+				t.Fatalf("caller = %#q, want suffix %#q", caller, expected)
+			}
+		})
+	}
+}
+
+func line() int {
+	_, _, l, _ := runtime.Caller(1)
+	return l
+}
+
+func logHelper(l Logger, message string) {
+	Helper()
+	l.Debug(context.Background(), message)
+}
+
+func logWrapper(l Logger, message string) {
+	l.Debug(context.Background(), message)
+}
+
+func logRecursiveHelper(l Logger, depth int, message string) {
+	Helper()
+	if depth > 0 {
+		logRecursiveHelper(l, depth-1, message)
+		return
+	}
+	l.Debug(context.Background(), message)
+}
+
+func logNestedWrapper(l Logger, message string) {
+	logWrapper(l.(*MicroLogger).WithCallerSkip(1), message)
+}
+
+func logrWrapper(l logr.Logger, message string) {
+	l.WithCallDepth(1).Info(message)
+}
+
+func logrHelper(l logr.Logger, message string) {
+	helper, l := l.WithCallStackHelper()
+	helper()
+	l.Info(message)
+}
+
+func Test_MicroLogger_WithCallerSkip(t *testing.T) {
+	w := &bytes.Buffer{}
+
+	logger, err := New(Config{IOWriter: w, Level: "info"})
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	l := logger.With("foo", "bar").(*MicroLogger).WithCallerSkip(1)
+	logWrapper(l, "dropped")
+	if w.Len() != 0 {
+		t.Fatalf("output = %#q, want %#q", w.String(), "")
+	}
+
+	l.(*MicroLogger).AsSink(1).WithName("component").(*LogrSink).WithCallDepth(1).Error(nil, "test")
+
+	var entry map[string]interface{}
+	err = json.Unmarshal(w.Bytes(), &entry)
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	if entry["foo"] != "bar" {
+		t.Fatalf("foo = %#v, want %#v", entry["foo"], "bar")
+	}
+	if entry["name"] != "component" {
+		t.Fatalf("name = %#v, want %#v", entry["name"], "component")
+	}
+}
+
+func Benchmark_MicroLogger_caller(b *testing.B) {
+	configs := []struct {
+		name   string
+		config Config
+	}{
+		{
+			name: "baseline",
+			config: Config{
+				Caller: func() interface{} {
+					return "helper_test.go:1"
+				},
+			},
+		},
+		{
+			name:   "default",
+			config: Config{},
+		},
+		{
+			name:   "split",
+			config: Config{CallerFormat: CallerFormat{Split: true}},
+		},
+	}
+
+	for _, c := range configs {
+		b.Run(c.name, func(b *testing.B) {
+			c.config.IOWriter = io.Discard
+			logger, err := New(c.config)
+			if err != nil {
+				b.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				logHelper(logger, "reconciling resource")
+			}
+		})
+	}
+}
+
+func Test_funcPackage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{
+			name:     "github.com/go-kit/log.(*context).Log",
+			expected: "github.com/go-kit/log",
+		},
+		{
+			name:     "github.com/giantswarm/micrologger.New",
+			expected: "github.com/giantswarm/micrologger",
+		},
+		{
+			name:     "net/http.(*conn).serve.func1",
+			expected: "net/http",
+		},
+		{
+			name:     "main.main",
+			expected: "main",
+		},
+	}
+
+	for i, tc := range testCases {
+		actual := funcPackage(tc.name)
+		if actual != tc.expected {
+			t.Fatalf("case %d expected %#q got %#q", i, tc.expected, actual)
+		}
+	}
+}
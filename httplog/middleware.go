@@ -0,0 +1,187 @@
+package httplog
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/micrologger/loggermeta"
+)
+
+type MiddlewareConfig struct {
+	Logger micrologger.Logger
+}
+
+// Middleware seeds the loggermeta of every request with its request ID,
+// method, path and remote address, and emits one access log per request.
+// Panics of the wrapped handler are recovered and logged as errors.
+type Middleware struct {
+	logger micrologger.Logger
+}
+
+func NewMiddleware(config MiddlewareConfig) (*Middleware, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	m := &Middleware{
+		logger: config.Logger,
+	}
+
+	return m, nil
+}
+
+// Handler wraps the given http.Handler. The request ID is read from the
+// X-Request-ID header or generated if missing, and always returned in the
+// X-Request-ID response header.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get(HeaderRequestID)
+		if id == "" {
+			id = newRequestID()
+		}
+
+		ctx := withMeta(r.Context(), map[string]string{
+			KeyMethod:     r.Method,
+			KeyPath:       r.URL.Path,
+			KeyRemoteAddr: r.RemoteAddr,
+			KeyRequestID:  id,
+		})
+
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+		rw.Header().Set(HeaderRequestID, id)
+
+		defer func() {
+			v := recover()
+			if v == http.ErrAbortHandler { //nolint:errorlint
+				panic(v)
+			}
+			if v != nil {
+				m.logger.LogCtx(ctx,
+					"level", "error",
+					"message", "recovered panic in http handler",
+					"stack", micrologger.PanicStack(v),
+				)
+
+				if !rw.wroteHeader {
+					http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}
+
+			m.logger.LogCtx(ctx,
+				"level", "info",
+				"message", "handled http request",
+				KeyStatus, rw.status,
+				KeyBytes, rw.bytes,
+				KeyLatency, time.Since(start).String(),
+			)
+		}()
+
+		next.ServeHTTP(rw, r.WithContext(ctx))
+	})
+}
+
+// responseWriter records the status code and the number of bytes written.
+type responseWriter struct {
+	http.ResponseWriter
+
+	bytes       int
+	status      int
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Flush implements http.Flusher in case the underlying http.ResponseWriter
+// does, so that streaming handlers keep working.
+func (w *responseWriter) Flush() {
+	f, ok := w.ResponseWriter.(http.Flusher)
+	if !ok {
+		return
+	}
+
+	w.wroteHeader = true
+	f.Flush()
+}
+
+// Hijack implements http.Hijacker in case the underlying http.ResponseWriter
+// does. Otherwise http.ErrNotSupported is returned.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	return h.Hijack()
+}
+
+// ReadFrom implements io.ReaderFrom using the underlying
+// http.ResponseWriter in case it does, e.g. to make use of sendfile.
+func (w *responseWriter) ReadFrom(r io.Reader) (int64, error) {
+	rf, ok := w.ResponseWriter.(io.ReaderFrom)
+	if !ok {
+		// Hide ReadFrom from io.Copy to not recurse.
+		return io.Copy(struct{ io.Writer }{w}, r)
+	}
+
+	w.wroteHeader = true
+	n, err := rf.ReadFrom(r)
+	w.bytes += int(n)
+	return n, err
+}
+
+// Unwrap makes the underlying http.ResponseWriter available to
+// http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// withMeta returns a new context.Context carrying a copy of the loggermeta of
+// ctx, if any, extended with keyVals.
+func withMeta(ctx context.Context, keyVals map[string]string) context.Context {
+	meta := loggermeta.New()
+	if m, ok := loggermeta.FromContext(ctx); ok {
+		for k, v := range m.KeyVals {
+			meta.KeyVals[k] = v
+		}
+	}
+	for k, v := range keyVals {
+		meta.KeyVals[k] = v
+	}
+
+	return loggermeta.NewContext(ctx, meta)
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+
+	return hex.EncodeToString(b)
+}
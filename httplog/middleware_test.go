@@ -0,0 +1,173 @@
+package httplog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/micrologger/loggermeta"
+)
+
+func Test_Middleware(t *testing.T) {
+	testCases := []struct {
+		name              string
+		requestID         string
+		handler           http.HandlerFunc
+		expectedStatus    int
+		expectedBytes     float64
+		expectedLevels    []string
+		expectedRequestID string
+	}{
+		{
+			name:      "case 0: request ID is taken from the request",
+			requestID: "foo",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				meta, ok := loggermeta.FromContext(r.Context())
+				if !ok {
+					t.Fatalf("ok = %v, want %v", ok, true)
+				}
+				_, _ = w.Write([]byte(meta.KeyVals[KeyRequestID]))
+			},
+			expectedStatus:    http.StatusOK,
+			expectedBytes:     3,
+			expectedLevels:    []string{"info"},
+			expectedRequestID: "foo",
+		},
+		{
+			name: "case 1: request ID is generated",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			},
+			expectedStatus: http.StatusTeapot,
+			expectedLevels: []string{"info"},
+		},
+		{
+			name:      "case 2: panics are recovered",
+			requestID: "bar",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				panic("test")
+			},
+			expectedStatus:    http.StatusInternalServerError,
+			expectedBytes:     22,
+			expectedLevels:    []string{"error", "info"},
+			expectedRequestID: "bar",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &bytes.Buffer{}
+
+			var m *Middleware
+			{
+				logger, err := micrologger.New(micrologger.Config{IOWriter: w})
+				if err != nil {
+					t.Fatalf("err = %v, want %v", err, nil)
+				}
+
+				m, err = NewMiddleware(MiddlewareConfig{Logger: logger})
+				if err != nil {
+					t.Fatalf("err = %v, want %v", err, nil)
+				}
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/test?foo=bar", nil)
+			if tc.requestID != "" {
+				req.Header.Set(HeaderRequestID, tc.requestID)
+			}
+			rec := httptest.NewRecorder()
+
+			m.Handler(tc.handler).ServeHTTP(rec, req)
+
+			requestID := rec.Header().Get(HeaderRequestID)
+			if requestID == "" {
+				t.Fatalf("request ID = %#q, want non-empty", requestID)
+			}
+			if tc.expectedRequestID != "" && requestID != tc.expectedRequestID {
+				t.Fatalf("request ID = %#q, want %#q", requestID, tc.expectedRequestID)
+			}
+
+			lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+			if len(lines) != len(tc.expectedLevels) {
+				t.Fatalf("lines = %d, want %d", len(lines), len(tc.expectedLevels))
+			}
+
+			var entry map[string]interface{}
+			for i, line := range lines {
+				entry = map[string]interface{}{}
+				err := json.Unmarshal([]byte(line), &entry)
+				if err != nil {
+					t.Fatalf("err = %v, want %v", err, nil)
+				}
+
+				if entry["level"] != tc.expectedLevels[i] {
+					t.Fatalf("level = %v, want %v", entry["level"], tc.expectedLevels[i])
+				}
+				if entry[KeyRequestID] != requestID {
+					t.Fatalf("request ID = %v, want %v", entry[KeyRequestID], requestID)
+				}
+				if entry[KeyPath] != "/test" {
+					t.Fatalf("path = %v, want %v", entry[KeyPath], "/test")
+				}
+				if entry[KeyMethod] != http.MethodGet {
+					t.Fatalf("method = %v, want %v", entry[KeyMethod], http.MethodGet)
+				}
+				if entry["level"] == "error" {
+					if _, ok := entry["stack"].(map[string]interface{}); !ok {
+						t.Fatalf("stack = %v, want object", entry["stack"])
+					}
+				}
+			}
+
+			// The access log is always the last entry.
+			if entry[KeyStatus] != float64(tc.expectedStatus) {
+				t.Fatalf("status = %v, want %v", entry[KeyStatus], tc.expectedStatus)
+			}
+			if entry[KeyBytes] != tc.expectedBytes {
+				t.Fatalf("bytes = %v, want %v", entry[KeyBytes], tc.expectedBytes)
+			}
+			if entry[KeyLatency] == nil {
+				t.Fatalf("latency = %v, want non-empty", entry[KeyLatency])
+			}
+		})
+	}
+}
+
+func Test_Middleware_Flush(t *testing.T) {
+	var m *Middleware
+	{
+		logger, err := micrologger.New(micrologger.Config{IOWriter: &bytes.Buffer{}})
+		if err != nil {
+			t.Fatalf("err = %v, want %v", err, nil)
+		}
+
+		m, err = NewMiddleware(MiddlewareConfig{Logger: logger})
+		if err != nil {
+			t.Fatalf("err = %v, want %v", err, nil)
+		}
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		f, ok := w.(http.Flusher)
+		if !ok {
+			t.Fatalf("ok = %v, want %v", ok, true)
+		}
+
+		_, _ = w.Write([]byte("test"))
+		f.Flush()
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	rec := httptest.NewRecorder()
+
+	m.Handler(http.HandlerFunc(handler)).ServeHTTP(rec, req)
+
+	if !rec.Flushed {
+		t.Fatalf("flushed = %v, want %v", rec.Flushed, true)
+	}
+}
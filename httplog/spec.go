@@ -0,0 +1,22 @@
+// Package httplog provides HTTP server middleware and a client transport which
+// log requests using micrologger and carry loggermeta values such as the
+// request ID across services.
+package httplog
+
+const (
+	// HeaderRequestID is the HTTP header carrying the request ID.
+	HeaderRequestID = "X-Request-ID"
+)
+
+const (
+	KeyAttempt    = "attempt"
+	KeyBytes      = "bytes"
+	KeyHTTPMethod = "http_method"
+	KeyLatency    = "latency"
+	KeyMethod     = "method"
+	KeyPath       = "path"
+	KeyRemoteAddr = "remote_addr"
+	KeyRequestID  = "request_id"
+	KeyStatus     = "status"
+	KeyURL        = "url"
+)
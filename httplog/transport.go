@@ -0,0 +1,150 @@
+package httplog
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/micrologger/loggermeta"
+)
+
+// attemptKey is an unexported type for the retry attempt key defined in this
+// package. This prevents collisions with keys defined in other packages.
+type attemptKey struct{}
+
+type TransportConfig struct {
+	Logger micrologger.Logger
+
+	// Headers maps loggermeta keys to the HTTP headers they are propagated
+	// as on outgoing requests. Defaults to propagating the request ID as
+	// X-Request-ID.
+	Headers map[string]string
+	// Level is the level outgoing requests are logged with. Defaults to
+	// debug.
+	Level string
+	// Underlying is the http.RoundTripper actually executing the requests.
+	// Defaults to http.DefaultTransport.
+	Underlying http.RoundTripper
+}
+
+// Transport is a http.RoundTripper which logs outgoing requests and
+// propagates loggermeta values as headers, so logs can be correlated across
+// services. The method of outgoing requests is logged under KeyHTTPMethod, so
+// that it is not overwritten by the method of the incoming request which
+// Middleware seeds into loggermeta under KeyMethod.
+type Transport struct {
+	logger     micrologger.Logger
+	headers    map[string]string
+	level      string
+	underlying http.RoundTripper
+}
+
+func NewTransport(config TransportConfig) (*Transport, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Headers == nil {
+		config.Headers = map[string]string{
+			KeyRequestID: HeaderRequestID,
+		}
+	}
+	if config.Level == "" {
+		config.Level = "debug"
+	}
+	if config.Underlying == nil {
+		config.Underlying = http.DefaultTransport
+	}
+
+	t := &Transport{
+		logger:     config.Logger,
+		headers:    config.Headers,
+		level:      config.Level,
+		underlying: config.Underlying,
+	}
+
+	return t, nil
+}
+
+// WithAttempt returns a new context.Context carrying the retry attempt of the
+// request it is used with. The attempt is logged by Transport and defaults to
+// 1.
+func WithAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	if meta, ok := loggermeta.FromContext(ctx); ok {
+		var cloned bool
+		for k, h := range t.headers {
+			v, ok := meta.KeyVals[k]
+			if !ok || req.Header.Get(h) != "" {
+				continue
+			}
+
+			// A http.RoundTripper must not modify the given request.
+			if !cloned {
+				req = req.Clone(ctx)
+				cloned = true
+			}
+			req.Header.Set(h, v)
+		}
+	}
+
+	attempt, ok := ctx.Value(attemptKey{}).(int)
+	if !ok {
+		attempt = 1
+	}
+
+	start := time.Now()
+	res, err := t.underlying.RoundTrip(req)
+	latency := time.Since(start).String()
+
+	if err != nil {
+		t.logger.LogCtx(ctx,
+			"level", t.level,
+			"message", "failed outgoing http request",
+			KeyHTTPMethod, req.Method,
+			KeyURL, redactURL(req.URL),
+			KeyAttempt, attempt,
+			KeyLatency, latency,
+			"error", err,
+		)
+
+		return nil, err
+	}
+
+	t.logger.LogCtx(ctx,
+		"level", t.level,
+		"message", "sent outgoing http request",
+		KeyHTTPMethod, req.Method,
+		KeyURL, redactURL(req.URL),
+		KeyAttempt, attempt,
+		KeyLatency, latency,
+		KeyStatus, res.StatusCode,
+	)
+
+	return res, nil
+}
+
+// redactURL renders the given URL with the values of the query parameters
+// and the password replaced, since they may carry secrets.
+func redactURL(u *url.URL) string {
+	c := *u
+
+	if c.RawQuery != "" {
+		q := c.Query()
+		for k := range q {
+			q[k] = []string{"xxxxx"}
+		}
+		c.RawQuery = q.Encode()
+	}
+
+	return c.Redacted()
+}
@@ -0,0 +1,161 @@
+package httplog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/micrologger/loggermeta"
+)
+
+func Test_Transport(t *testing.T) {
+	var receivedRequestID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedRequestID = r.Header.Get(HeaderRequestID)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	w := &bytes.Buffer{}
+
+	var client *http.Client
+	{
+		logger, err := micrologger.New(micrologger.Config{IOWriter: w})
+		if err != nil {
+			t.Fatalf("err = %v, want %v", err, nil)
+		}
+
+		transport, err := NewTransport(TransportConfig{Logger: logger, Level: "info"})
+		if err != nil {
+			t.Fatalf("err = %v, want %v", err, nil)
+		}
+
+		client = &http.Client{Transport: transport}
+	}
+
+	var ctx context.Context
+	{
+		meta := loggermeta.New()
+		meta.KeyVals[KeyRequestID] = "foo"
+
+		ctx = loggermeta.NewContext(context.Background(), meta)
+		ctx = WithAttempt(ctx, 2)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/test?token=secret", nil)
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+	res.Body.Close()
+
+	if receivedRequestID != "foo" {
+		t.Fatalf("request ID = %#q, want %#q", receivedRequestID, "foo")
+	}
+	if req.Header.Get(HeaderRequestID) != "" {
+		t.Fatalf("original request was modified")
+	}
+
+	var entry map[string]interface{}
+	err = json.Unmarshal(w.Bytes(), &entry)
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	if entry["level"] != "info" {
+		t.Fatalf("level = %v, want %v", entry["level"], "info")
+	}
+	if entry[KeyStatus] != float64(http.StatusAccepted) {
+		t.Fatalf("status = %v, want %v", entry[KeyStatus], http.StatusAccepted)
+	}
+	if entry[KeyAttempt] != float64(2) {
+		t.Fatalf("attempt = %v, want %v", entry[KeyAttempt], 2)
+	}
+	if entry[KeyRequestID] != "foo" {
+		t.Fatalf("request ID = %v, want %v", entry[KeyRequestID], "foo")
+	}
+	u, _ := entry[KeyURL].(string)
+	if strings.Contains(u, "secret") || !strings.HasSuffix(u, "/test?token=xxxxx") {
+		t.Fatalf("url = %v, want redacted query", u)
+	}
+}
+
+func Test_Transport_Middleware(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer backend.Close()
+
+	w := &bytes.Buffer{}
+
+	var handler http.Handler
+	{
+		logger, err := micrologger.New(micrologger.Config{IOWriter: w})
+		if err != nil {
+			t.Fatalf("err = %v, want %v", err, nil)
+		}
+
+		transport, err := NewTransport(TransportConfig{Logger: logger, Level: "info"})
+		if err != nil {
+			t.Fatalf("err = %v, want %v", err, nil)
+		}
+
+		middleware, err := NewMiddleware(MiddlewareConfig{Logger: logger})
+		if err != nil {
+			t.Fatalf("err = %v, want %v", err, nil)
+		}
+
+		client := &http.Client{Transport: transport}
+		handler = middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			req, err := http.NewRequestWithContext(r.Context(), http.MethodDelete, backend.URL+"/test", nil)
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			res, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+			res.Body.Close()
+		}))
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/incoming", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("len(lines) = %d, want %d", len(lines), 2)
+	}
+
+	var entry map[string]interface{}
+	err := json.Unmarshal([]byte(lines[0]), &entry)
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	if entry["message"] != "sent outgoing http request" {
+		t.Fatalf("message = %v, want %v", entry["message"], "sent outgoing http request")
+	}
+	if entry[KeyHTTPMethod] != http.MethodDelete {
+		t.Fatalf("http method = %v, want %v", entry[KeyHTTPMethod], http.MethodDelete)
+	}
+	if entry[KeyMethod] != http.MethodPost {
+		t.Fatalf("method = %v, want %v", entry[KeyMethod], http.MethodPost)
+	}
+	if entry[KeyPath] != "/incoming" {
+		t.Fatalf("path = %v, want %v", entry[KeyPath], "/incoming")
+	}
+	if u, _ := entry[KeyURL].(string); !strings.HasSuffix(u, "/test") {
+		t.Fatalf("url = %v, want suffix %v", u, "/test")
+	}
+}
@@ -1,14 +1,181 @@
 package micrologger
 
 import (
-	"fmt"
+	"path"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	KeyCaller     = "caller"
+	KeyCallerFile = "caller_file"
+	KeyCallerFunc = "caller_func"
+	KeyCallerLine = "caller_line"
+)
 
-	kitlog "github.com/go-kit/log"
-	"github.com/go-stack/stack"
+const (
+	// CallerPathDefault renders the path of the source file relative to the
+	// compile time GOPATH or module cache, e.g.
+	// "github.com/giantswarm/micrologger/logger.go".
+	CallerPathDefault CallerPath = iota
+	// CallerPathShort renders the name of the source file only, e.g.
+	// "logger.go".
+	CallerPathShort
+	// CallerPathFull renders the full path of the source file, e.g.
+	// "/home/user/go/src/github.com/giantswarm/micrologger/logger.go".
+	CallerPathFull
+	// CallerPathModule renders the path of the source file relative to the
+	// root of its module, e.g. "loggermeta/logger_meta.go".
+	CallerPathModule
 )
 
-func newCallerFunc(skip int) kitlog.Valuer {
+// CallerPath defines how the path of the source file of the caller is
+// rendered.
+type CallerPath int
+
+type CallerFormat struct {
+	// Path defines how the path of the source file is rendered. Defaults to
+	// CallerPathDefault.
+	Path CallerPath
+	// Func appends the package qualified function name of the caller to the
+	// "caller" key, e.g. "logger.go:42 micrologger.New".
+	Func bool
+	// Split writes the "caller_file", "caller_line" and "caller_func" keys
+	// instead of the "caller" key.
+	Split bool
+}
+
+// callerFormatter renders the caller of logging calls as configured by
+// CallerFormat and StackConfig.
+type callerFormatter struct {
+	format CallerFormat
+	stack  stackFormatter
+}
+
+// resolve returns the caller keys and values of the current logging call.
+// The given skip is the number of frames skipped in addition to the frames of
+// helpers.
+func (f callerFormatter) resolve(skip int) []interface{} {
+	c := f.call(skip)
+
+	if !f.format.Split {
+		return []interface{}{
+			KeyCaller, f.caller(c),
+		}
+	}
+
+	return []interface{}{
+		KeyCallerFile, f.file(c),
+		KeyCallerLine, c.Line,
+		KeyCallerFunc, f.function(c),
+	}
+}
+
+// call returns the caller of the logging call. Frames of helpers and of
+// collapsed packages are skipped. See findCaller.
+func (f callerFormatter) call(skip int) runtime.Frame {
+	if len(f.stack.config.CollapsePackages) != 0 {
+		return findCaller(skip, f.stack.collapsedFrame)
+	}
+
+	return findCaller(skip, nil)
+}
+
+func (f callerFormatter) file(c runtime.Frame) string {
+	var file string
+	switch f.format.Path {
+	case CallerPathShort:
+		file = path.Base(c.File)
+	case CallerPathFull:
+		file = c.File
+	case CallerPathModule:
+		file = moduleRelativeFile(c)
+	default:
+		file = packageFile(c)
+	}
+
+	return f.stack.trimFile(file)
+}
+
+func (f callerFormatter) function(c runtime.Frame) string {
+	return c.Function[strings.LastIndex(c.Function, "/")+1:]
+}
+
+func (f callerFormatter) caller(c runtime.Frame) string {
+	caller := f.file(c) + ":" + strconv.Itoa(c.Line)
+	if f.format.Func {
+		caller += " " + f.function(c)
+	}
+
+	return caller
+}
+
+func newCallerFunc(skip int, f callerFormatter) func() interface{} {
 	return func() interface{} {
-		return fmt.Sprintf("%+v", stack.Caller(5+skip))
+		return f.caller(f.call(skip))
+	}
+}
+
+var (
+	modulePathsOnce sync.Once
+	modulePaths     []string
+)
+
+// moduleRelativeFile renders the path of the source file of the given frame
+// relative to the root of its module. Frames of packages not belonging to any
+// module known from the build info are rendered like CallerPathDefault.
+func moduleRelativeFile(c runtime.Frame) string {
+	modulePathsOnce.Do(func() {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+
+		modulePaths = append(modulePaths, info.Main.Path)
+		for _, d := range info.Deps {
+			modulePaths = append(modulePaths, d.Path)
+		}
+	})
+
+	pkg := funcPackage(c.Function)
+
+	var module string
+	for _, m := range modulePaths {
+		if m == "" || len(m) <= len(module) {
+			continue
+		}
+		if pkg == m || strings.HasPrefix(pkg, m+"/") {
+			module = m
+		}
+	}
+
+	if module == "" {
+		return packageFile(c)
 	}
+
+	rel := strings.TrimPrefix(strings.TrimPrefix(pkg, module), "/")
+	return path.Join(rel, path.Base(c.File))
+}
+
+// packageFile renders the path of the source file of the given frame
+// prefixed with the import path of its package, e.g.
+// "github.com/giantswarm/micrologger/logger.go". The last segment of the
+// import path is taken from the file path since it may differ from the
+// package name.
+func packageFile(c runtime.Frame) string {
+	// The last two segments of the file path are its directory and name.
+	file := c.File
+	if i := strings.LastIndex(file, "/"); i != -1 {
+		file = file[strings.LastIndex(file[:i], "/")+1:]
+	}
+
+	i := strings.LastIndex(c.Function, "/")
+	if i == -1 {
+		return file
+	}
+
+	return c.Function[:i] + "/" + file
 }
@@ -0,0 +1,102 @@
+package micrologger
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func Test_MicroLogger_CallerFormat(t *testing.T) {
+	testCases := []struct {
+		name     string
+		format   CallerFormat
+		expected map[string]*regexp.Regexp
+	}{
+		{
+			name:   "case 0: default format",
+			format: CallerFormat{},
+			expected: map[string]*regexp.Regexp{
+				KeyCaller: regexp.MustCompile(`^\S+/key_test\.go:\d+$`),
+			},
+		},
+		{
+			name: "case 1: short format with function",
+			format: CallerFormat{
+				Path: CallerPathShort,
+				Func: true,
+			},
+			expected: map[string]*regexp.Regexp{
+				KeyCaller: regexp.MustCompile(`^key_test\.go:\d+ micrologger\.Test_MicroLogger_CallerFormat\.func1$`),
+			},
+		},
+		{
+			name: "case 2: full format",
+			format: CallerFormat{
+				Path: CallerPathFull,
+			},
+			expected: map[string]*regexp.Regexp{
+				KeyCaller: regexp.MustCompile(`^/\S+/key_test\.go:\d+$`),
+			},
+		},
+		{
+			name: "case 3: module format",
+			format: CallerFormat{
+				Path: CallerPathModule,
+			},
+			expected: map[string]*regexp.Regexp{
+				KeyCaller: regexp.MustCompile(`^key_test\.go:\d+$`),
+			},
+		},
+		{
+			name: "case 4: split keys",
+			format: CallerFormat{
+				Path:  CallerPathShort,
+				Split: true,
+			},
+			expected: map[string]*regexp.Regexp{
+				KeyCallerFile: regexp.MustCompile(`^key_test\.go$`),
+				KeyCallerLine: regexp.MustCompile(`^\d+$`),
+				KeyCallerFunc: regexp.MustCompile(`^micrologger\.Test_MicroLogger_CallerFormat\.func1$`),
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &bytes.Buffer{}
+
+			logger, err := New(Config{IOWriter: w, CallerFormat: tc.format})
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			logger.Log("foo", "bar")
+
+			var entry map[string]interface{}
+			err = json.Unmarshal(w.Bytes(), &entry)
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			for k, r := range tc.expected {
+				var s string
+				switch v := entry[k].(type) {
+				case string:
+					s = v
+				case float64:
+					s = strconv.Itoa(int(v))
+				}
+				if !r.MatchString(s) {
+					t.Fatalf("%s = %#q, want match for %#q", k, s, r.String())
+				}
+			}
+			if tc.format.Split {
+				if _, ok := entry[KeyCaller]; ok {
+					t.Fatalf("caller = %#v, want not set", entry[KeyCaller])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package micrologger
+
+import (
+	"fmt"
+
+	"github.com/giantswarm/microerror"
+	kitlog "github.com/go-kit/log"
+)
+
+const (
+	KeyMicrologgerError = "micrologger_error"
+)
+
+// KeyValsMode defines how invalid key-value pairs passed to Log, LogCtx, With
+// and LogrSink are handled. Key-value pairs are invalid when their number is
+// odd or when keys are not strings.
+type KeyValsMode int
+
+const (
+	// KeyValsKeep writes invalid key-value pairs as they are. A missing
+	// value is written as "(MISSING)" and keys are formatted using fmt.
+	KeyValsKeep KeyValsMode = iota
+	// KeyValsAnnotate writes invalid key-value pairs like KeyValsKeep and
+	// additionally describes the problem under the "micrologger_error" key.
+	KeyValsAnnotate
+	// KeyValsPanic panics with an invalidKeyValsError, e.g. to catch
+	// mistakes in tests.
+	KeyValsPanic
+	// KeyValsHook writes invalid key-value pairs like KeyValsKeep and calls
+	// Config.KeyValsHook with an invalidKeyValsError.
+	KeyValsHook
+)
+
+// checkKeyVals applies the configured KeyValsMode to the given keyVals. A
+// missing value is always added explicitly, so that key-value pairs appended
+// later, e.g. from loggermeta, stay aligned. The given keyVals are not
+// mutated.
+func (l *MicroLogger) checkKeyVals(keyVals []interface{}) []interface{} {
+	problem := keyValsProblem(keyVals)
+	if problem == "" {
+		return keyVals
+	}
+
+	var kvs []interface{}
+	{
+		kvs = append(kvs, keyVals...)
+		if len(kvs)%2 != 0 {
+			kvs = append(kvs, kitlog.ErrMissingValue)
+		}
+	}
+
+	switch l.keyValsMode {
+	case KeyValsAnnotate:
+		kvs = append(kvs, KeyMicrologgerError, problem)
+	case KeyValsPanic:
+		panic(microerror.Maskf(invalidKeyValsError, "%s", problem))
+	case KeyValsHook:
+		l.keyValsHook(microerror.Maskf(invalidKeyValsError, "%s", problem))
+	}
+
+	return kvs
+}
+
+// keyValsProblem describes why the given keyVals are invalid or returns an
+// empty string.
+func keyValsProblem(keyVals []interface{}) string {
+	if len(keyVals)%2 != 0 {
+		return "odd number of keyvals"
+	}
+
+	for i := 0; i < len(keyVals); i += 2 {
+		if _, ok := keyVals[i].(string); !ok {
+			return fmt.Sprintf("non-string key %v of type %T", keyVals[i], keyVals[i])
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,150 @@
+package micrologger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/giantswarm/micrologger/loggermeta"
+)
+
+func Test_MicroLogger_keyValsMode(t *testing.T) {
+	testCases := []struct {
+		name          string
+		mode          KeyValsMode
+		log           func(l *MicroLogger)
+		expected      map[string]interface{}
+		expectedHook  int
+		expectedPanic bool
+	}{
+		{
+			name: "case 0: keep odd number of keyvals aligned with meta",
+			mode: KeyValsKeep,
+			log: func(l *MicroLogger) {
+				meta := loggermeta.New()
+				meta.KeyVals["request"] = "1"
+				l.LogCtx(loggermeta.NewContext(context.Background(), meta), "foo", "bar", "baz")
+			},
+			expected: map[string]interface{}{"foo": "bar", "baz": "(MISSING)", "request": "1"},
+		},
+		{
+			name: "case 1: annotate odd number of keyvals",
+			mode: KeyValsAnnotate,
+			log: func(l *MicroLogger) {
+				l.Log("foo", "bar", "baz")
+			},
+			expected: map[string]interface{}{"foo": "bar", "baz": "(MISSING)", KeyMicrologgerError: "odd number of keyvals"},
+		},
+		{
+			name: "case 2: annotate non-string keys in With",
+			mode: KeyValsAnnotate,
+			log: func(l *MicroLogger) {
+				l.With(1, "one").Log("foo", "bar")
+			},
+			expected: map[string]interface{}{"foo": "bar", "1": "one", KeyMicrologgerError: "non-string key 1 of type int"},
+		},
+		{
+			name: "case 3: annotate odd number of logr values",
+			mode: KeyValsAnnotate,
+			log: func(l *MicroLogger) {
+				logr.New(l.AsSink(0)).WithValues("foo").Error(nil, "test")
+			},
+			expected: map[string]interface{}{"foo": "(MISSING)", "level": "error", "message": "test", "name": "", KeyMicrologgerError: "odd number of keyvals"},
+		},
+		{
+			name: "case 4: hook",
+			mode: KeyValsHook,
+			log: func(l *MicroLogger) {
+				logr.New(l.AsSink(0)).Error(nil, "test", "foo")
+			},
+			expected:     map[string]interface{}{"foo": "(MISSING)", "level": "error", "message": "test", "name": ""},
+			expectedHook: 1,
+		},
+		{
+			name: "case 5: hook called once for logr errors",
+			mode: KeyValsHook,
+			log: func(l *MicroLogger) {
+				logr.New(l.AsSink(0)).Error(nil, "test", 1, "one")
+			},
+			expected:     map[string]interface{}{"1": "one", "level": "error", "message": "test", "name": ""},
+			expectedHook: 1,
+		},
+		{
+			name: "case 6: panic",
+			mode: KeyValsPanic,
+			log: func(l *MicroLogger) {
+				l.Log("foo")
+			},
+			expectedPanic: true,
+		},
+		{
+			name: "case 7: valid keyvals",
+			mode: KeyValsPanic,
+			log: func(l *MicroLogger) {
+				l.Log("foo", "bar")
+			},
+			expected: map[string]interface{}{"foo": "bar"},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &bytes.Buffer{}
+
+			var hook int
+			logger, err := New(Config{
+				IOWriter:    w,
+				KeyValsMode: tc.mode,
+				KeyValsHook: func(err error) {
+					if !IsInvalidKeyVals(err) {
+						t.Fatalf("err = %v, want %v", err, invalidKeyValsError)
+					}
+					hook++
+				},
+			})
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			var panicked bool
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						if err, ok := r.(error); !ok || !IsInvalidKeyVals(err) {
+							t.Fatalf("recovered = %v, want %v", r, invalidKeyValsError)
+						}
+						panicked = true
+					}
+				}()
+				tc.log(logger)
+			}()
+
+			if panicked != tc.expectedPanic {
+				t.Fatalf("panicked = %v, want %v", panicked, tc.expectedPanic)
+			}
+			if hook != tc.expectedHook {
+				t.Fatalf("hook = %d, want %d", hook, tc.expectedHook)
+			}
+			if tc.expectedPanic {
+				return
+			}
+
+			var entry map[string]interface{}
+			err = json.Unmarshal(w.Bytes(), &entry)
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+			delete(entry, KeyCaller)
+			delete(entry, "time")
+
+			if !cmp.Equal(entry, tc.expected) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expected, entry))
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package micrologger
+
+import (
+	kitlog "github.com/go-kit/log"
+)
+
+// Lazy is a value which is only computed when the record carrying it is
+// written, so that expensive diagnostics cost nothing for dropped records.
+// Lazy values are evaluated just like kitlog.Valuer values, both for values
+// added using With and for values passed to logging calls. Returned errors
+// are rendered like error values.
+type Lazy func() interface{}
+
+// evaluateLazy replaces Lazy and kitlog.Valuer values in keyVals with their
+// results. The given keyVals are not mutated.
+func (f stackFormatter) evaluateLazy(keyVals []interface{}) []interface{} {
+	var keyValsCopy []interface{}
+
+	for i := 1; i < len(keyVals); i += 2 {
+		var v Lazy
+		switch x := keyVals[i].(type) {
+		case Lazy:
+			v = x
+		case kitlog.Valuer:
+			v = Lazy(x)
+		default:
+			continue
+		}
+
+		if keyValsCopy == nil {
+			keyValsCopy = append([]interface{}{}, keyVals...)
+		}
+		keyValsCopy[i] = f.evaluate(v)
+	}
+
+	if keyValsCopy == nil {
+		return keyVals
+	}
+
+	return keyValsCopy
+}
+
+// evaluateLazyFields replaces the values of object fields holding Lazy and
+// kitlog.Valuer values with their results. The given fields are not mutated.
+func (f stackFormatter) evaluateLazyFields(fields []Field) []Field {
+	var fieldsCopy []Field
+
+	for i, field := range fields {
+		if field.kind != fieldObject {
+			continue
+		}
+
+		var v Lazy
+		switch x := field.obj.(type) {
+		case Lazy:
+			v = x
+		case kitlog.Valuer:
+			v = Lazy(x)
+		default:
+			continue
+		}
+
+		if fieldsCopy == nil {
+			fieldsCopy = append([]Field{}, fields...)
+		}
+		fieldsCopy[i] = Object(field.Key, f.evaluate(v))
+	}
+
+	if fieldsCopy == nil {
+		return fields
+	}
+
+	return fieldsCopy
+}
+
+func (f stackFormatter) evaluate(v Lazy) interface{} {
+	if v == nil {
+		return nil
+	}
+
+	result := v()
+	if err, ok := result.(error); ok && err != nil {
+		return f.renderError(err, 0)
+	}
+
+	return result
+}
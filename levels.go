@@ -0,0 +1,146 @@
+package micrologger
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/giantswarm/microerror"
+)
+
+type LevelsConfig struct {
+	// Levels maps logger name patterns to levels. See Levels.Set.
+	Levels map[string]string
+}
+
+// Levels is a registry of per component levels. Loggers configured using
+// Config.Levels look up the level of their name, as given to Named and
+// LogrSink.WithName, and fall back to Config.Level for names without a
+// matching pattern. Levels can be changed at runtime and are safe for
+// concurrent use. Lookups are cached per name until the next change.
+type Levels struct {
+	mutex    sync.Mutex
+	patterns map[string]levelID
+
+	// cache maps logger names to their levelID, or to zero for names
+	// without a matching pattern. It is replaced on every change.
+	cache atomic.Pointer[sync.Map]
+}
+
+func NewLevels(config LevelsConfig) (*Levels, error) {
+	l := &Levels{
+		patterns: map[string]levelID{},
+	}
+	l.cache.Store(&sync.Map{})
+
+	for p, level := range config.Levels {
+		err := l.Set(p, level)
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "%T.Levels[%#q] is invalid: %s", config, p, err)
+		}
+	}
+
+	return l, nil
+}
+
+// Set configures the given level for loggers whose dotted name, or one of its
+// parents, matches pattern. The pattern "resource" matches the names
+// "resource" and "resource.endpoint". Patterns may contain wildcards of
+// path.Match, which do not match across dots, e.g. "resource.*.cache". When
+// several patterns match, the one matching the longest name wins and literal
+// patterns win over wildcard patterns.
+func (l *Levels) Set(pattern string, level string) error {
+	if pattern == "" {
+		return microerror.Maskf(invalidLevelError, "pattern must not be empty")
+	}
+	_, err := path.Match(namePath(pattern), "")
+	if err != nil {
+		return microerror.Maskf(invalidLevelError, "pattern %#q is malformed", pattern)
+	}
+	id, ok := levelMapping[level]
+	if !ok {
+		return microerror.Maskf(invalidLevelError, "level must be one of debug, info, warning, error or fatal, got %#q", level)
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.patterns[pattern] = id
+	l.cache.Store(&sync.Map{})
+
+	return nil
+}
+
+// Delete removes the given pattern.
+func (l *Levels) Delete(pattern string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.patterns, pattern)
+	l.cache.Store(&sync.Map{})
+}
+
+// level returns the levelID configured for the given logger name.
+func (l *Levels) level(name string) (levelID, bool) {
+	cache := l.cache.Load()
+	if v, ok := cache.Load(name); ok {
+		id := v.(levelID)
+		return id, id != 0
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	id := l.match(name)
+	// The cache may have been replaced by a concurrent change, in which
+	// case the result must not be stored in the current cache.
+	if l.cache.Load() == cache {
+		cache.Store(name, id)
+	}
+
+	return id, id != 0
+}
+
+// match returns the levelID of the best matching pattern or zero.
+func (l *Levels) match(name string) levelID {
+	segments := strings.Split(name, ".")
+	for n := len(segments); n > 0; n-- {
+		prefix := namePath(strings.Join(segments[:n], "."))
+
+		var matches []string
+		for p := range l.patterns {
+			ok, _ := path.Match(namePath(p), prefix)
+			if ok {
+				matches = append(matches, p)
+			}
+		}
+		if len(matches) == 0 {
+			continue
+		}
+
+		// Literal patterns win, otherwise the order is lexical so that
+		// lookups are deterministic.
+		sort.Slice(matches, func(i, j int) bool {
+			if hasWildcard(matches[i]) != hasWildcard(matches[j]) {
+				return !hasWildcard(matches[i])
+			}
+			return matches[i] < matches[j]
+		})
+
+		return l.patterns[matches[0]]
+	}
+
+	return 0
+}
+
+// namePath converts dotted names to slash separated paths, so that wildcards
+// of path.Match do not match across dots.
+func namePath(name string) string {
+	return strings.ReplaceAll(name, ".", "/")
+}
+
+func hasWildcard(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
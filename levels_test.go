@@ -0,0 +1,144 @@
+package micrologger
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"testing"
+)
+
+func Test_Levels_level(t *testing.T) {
+	testCases := []struct {
+		name          string
+		levels        map[string]string
+		loggerName    string
+		expectedLevel levelID
+		expectedOK    bool
+	}{
+		{
+			name:       "case 0: no patterns",
+			loggerName: "resource",
+		},
+		{
+			name:          "case 1: exact name",
+			levels:        map[string]string{"resource": "debug"},
+			loggerName:    "resource",
+			expectedLevel: levelDebug,
+			expectedOK:    true,
+		},
+		{
+			name:          "case 2: parent name",
+			levels:        map[string]string{"resource": "debug"},
+			loggerName:    "resource.endpoint",
+			expectedLevel: levelDebug,
+			expectedOK:    true,
+		},
+		{
+			name:       "case 3: prefix not at a dot",
+			levels:     map[string]string{"resource": "debug"},
+			loggerName: "resources",
+		},
+		{
+			name:          "case 4: longest name wins",
+			levels:        map[string]string{"resource": "warning", "resource.endpoint": "debug"},
+			loggerName:    "resource.endpoint.cache",
+			expectedLevel: levelDebug,
+			expectedOK:    true,
+		},
+		{
+			name:          "case 5: wildcard",
+			levels:        map[string]string{"resource.*.cache": "debug"},
+			loggerName:    "resource.endpoint.cache",
+			expectedLevel: levelDebug,
+			expectedOK:    true,
+		},
+		{
+			name:       "case 6: wildcard does not match across dots",
+			levels:     map[string]string{"resource.*": "debug"},
+			loggerName: "resource",
+		},
+		{
+			name:          "case 7: literal wins over wildcard",
+			levels:        map[string]string{"resource.*": "warning", "resource.endpoint": "debug"},
+			loggerName:    "resource.endpoint",
+			expectedLevel: levelDebug,
+			expectedOK:    true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			levels, err := NewLevels(LevelsConfig{Levels: tc.levels})
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			// Look up twice to cover cached lookups.
+			for j := 0; j < 2; j++ {
+				level, ok := levels.level(tc.loggerName)
+				if level != tc.expectedLevel {
+					t.Fatalf("level = %v, want %v", level, tc.expectedLevel)
+				}
+				if ok != tc.expectedOK {
+					t.Fatalf("ok = %v, want %v", ok, tc.expectedOK)
+				}
+			}
+		})
+	}
+}
+
+func Test_Levels_Set(t *testing.T) {
+	levels, err := NewLevels(LevelsConfig{})
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	err = levels.Set("resource", "verbose")
+	if !IsInvalidLevel(err) {
+		t.Fatalf("err = %v, want %v", err, invalidLevelError)
+	}
+	err = levels.Set("resource[", "debug")
+	if !IsInvalidLevel(err) {
+		t.Fatalf("err = %v, want %v", err, invalidLevelError)
+	}
+
+	_, err = NewLevels(LevelsConfig{Levels: map[string]string{"": "debug"}})
+	if !IsInvalidConfig(err) {
+		t.Fatalf("err = %v, want %v", err, invalidConfigError)
+	}
+
+	w := &bytes.Buffer{}
+	logger, err := New(Config{IOWriter: w, Level: "info", Levels: levels})
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+	endpoint := logger.Named("resource").Named("endpoint")
+
+	endpoint.Debug(context.Background(), "test")
+	if w.Len() != 0 {
+		t.Fatalf("output = %#q, want %#q", w.String(), "")
+	}
+
+	err = levels.Set("resource.endpoint", "debug")
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	endpoint.Debug(context.Background(), "test")
+	if w.Len() == 0 {
+		t.Fatalf("output = %#q, want record", w.String())
+	}
+
+	w.Reset()
+	logger.Named("resource").Debug(context.Background(), "test")
+	if w.Len() != 0 {
+		t.Fatalf("output = %#q, want %#q", w.String(), "")
+	}
+
+	levels.Delete("resource.endpoint")
+
+	endpoint.Debug(context.Background(), "test")
+	if w.Len() != 0 {
+		t.Fatalf("output = %#q, want %#q", w.String(), "")
+	}
+}
@@ -6,48 +6,183 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"os"
+	"time"
 
 	"github.com/giantswarm/microerror"
-	kitlog "github.com/go-kit/log"
 	"github.com/go-logr/logr"
 
 	"github.com/giantswarm/micrologger/loggermeta"
 )
 
 type Config struct {
-	Caller             kitlog.Valuer
+	// Caller is a custom function rendering the "caller" key, called once per
+	// record. When configured CallerFormat, WithCallerSkip and
+	// WithIncreasedCallerDepth have no effect on the caller.
+	Caller             func() interface{}
 	IOWriter           io.Writer
-	TimestampFormatter kitlog.Valuer
+	TimestampFormatter func() interface{}
+
+	// Handler writes the records of the logger. Defaults to a JSONHandler
+	// writing to IOWriter and FallbackWriter. When configured IOWriter and
+	// FallbackWriter have no effect and SetOutput only flushes the handler
+	// in case it implements Flusher.
+	Handler Handler
+
+	// CallerFormat configures how the caller is rendered, unless a custom
+	// Caller is configured.
+	CallerFormat CallerFormat
+	// Stack configures how stack frames are rendered under the "stack" key,
+	// in error values and under the "caller" key, unless a custom Caller is
+	// configured. The zero value renders stack frames unchanged.
+	Stack StackConfig
+
+	// Level is the minimum level of records being written. It is one of
+	// debug, info, warning, error or fatal. Records without a known level are
+	// always written. Calls made with a context.Context escalated using
+	// WithDebug are always written. Defaults to writing all records.
+	Level string
+	// Levels overrides Level for named loggers, see Named. Levels can be
+	// shared by several loggers and changed at runtime.
+	Levels *Levels
+
+	// ErrorLevels classify errors logged using Error and Errorf. The first
+	// matching ErrorLevel determines the level of the record and whether
+	// the "stack" key is written. Errors not matching any ErrorLevel are
+	// logged in error level with their stack.
+	ErrorLevels []ErrorLevel
+
+	// KeyValsMode configures how odd numbers of key-value pairs and non-string
+	// keys passed to Log, LogCtx, With and LogrSink are handled. Defaults to
+	// KeyValsKeep.
+	KeyValsMode KeyValsMode
+	// KeyValsHook is called with an invalidKeyValsError for invalid
+	// key-value pairs in KeyValsHook mode.
+	KeyValsHook func(err error)
+
+	// Processors process every record which passes the configured level
+	// before it is encoded, in the given order. See Processor.
+	Processors []Processor
+
+	// LogrLevel maps the V-level of Info records written using LogrSink to
+	// the level of the record, usually info or debug. Defaults to
+	// DefaultLogrLevel.
+	LogrLevel func(verbosity int) string
+
+	// ErrorHandler is called with records which could not be encoded or
+	// written, together with an encodeFailedError or writeFailedError. Failed
+	// flushes are reported without record. Defaults to DefaultErrorHandler.
+	ErrorHandler func(err error, keyVals []interface{})
+	// FallbackWriter receives records which could not be written to the
+	// IOWriter, e.g. os.Stderr. Defaults to no fallback.
+	FallbackWriter io.Writer
+
+	// Exit is called by Fatal and Fatalf with exit code 1 after the record
+	// is written and the IOWriter is flushed. Defaults to os.Exit.
+	Exit func(code int)
 }
 
 type MicroLogger struct {
-	info      logr.RuntimeInfo
-	logger    kitlog.Logger
-	verbosity int
-	names     []string
+	info         logr.RuntimeInfo
+	handler      Handler
+	timestamp    func() interface{}
+	keyVals      []interface{}
+	processors   []Processor
+	writer       *syncWriter
+	errorHandler func(err error, keyVals []interface{})
+	stats        *stats
+	exit         func(code int)
+	level        levelID
+	levels       *Levels
+	errorLevels  []ErrorLevel
+	caller       callerFormatter
+	callerSkip   int
+	customCaller func() interface{}
+	stack        stackFormatter
+	keyValsMode  KeyValsMode
+	keyValsHook  func(err error)
+	logrLevel    func(verbosity int) string
+	verbosity    int
+	name         string
 }
 
 func New(config Config) (*MicroLogger, error) {
-	if config.Caller == nil {
-		config.Caller = DefaultCaller
+	var level levelID
+	if config.Level != "" {
+		var ok bool
+		level, ok = levelMapping[config.Level]
+		if !ok {
+			return nil, microerror.Maskf(invalidConfigError, "%T.Level must be one of debug, info, warning, error or fatal, got %#q", config, config.Level)
+		}
+	}
+
+	errorLevels := append([]ErrorLevel{}, config.ErrorLevels...)
+	for i, e := range errorLevels {
+		if e.Kind == "" && e.Matcher == nil {
+			return nil, microerror.Maskf(invalidConfigError, "%T.ErrorLevels[%d] must have Kind or Matcher", config, i)
+		}
+		if e.Level == "" {
+			errorLevels[i].Level = "error"
+		} else if _, ok := levelMapping[e.Level]; !ok {
+			return nil, microerror.Maskf(invalidConfigError, "%T.ErrorLevels[%d].Level must be one of debug, info, warning, error or fatal, got %#q", config, i, e.Level)
+		}
 	}
+
+	if config.KeyValsMode < KeyValsKeep || config.KeyValsMode > KeyValsHook {
+		return nil, microerror.Maskf(invalidConfigError, "%T.KeyValsMode must be a known KeyValsMode, got %d", config, config.KeyValsMode)
+	}
+	if config.KeyValsMode == KeyValsHook && config.KeyValsHook == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.KeyValsHook must not be empty in KeyValsHook mode", config)
+	}
+
+	stack := stackFormatter{config: config.Stack}
+	caller := callerFormatter{format: config.CallerFormat, stack: stack}
+
 	if config.TimestampFormatter == nil {
 		config.TimestampFormatter = DefaultTimestampFormatter
 	}
 	if config.IOWriter == nil {
 		config.IOWriter = DefaultIOWriter
 	}
+	if config.LogrLevel == nil {
+		config.LogrLevel = DefaultLogrLevel
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = DefaultErrorHandler
+	}
+	if config.Exit == nil {
+		config.Exit = os.Exit
+	}
 
-	kitLogger := kitlog.NewJSONLogger(kitlog.NewSyncWriter(config.IOWriter))
-	kitLogger = kitlog.With(
-		kitLogger,
-		"caller", config.Caller,
-		"time", config.TimestampFormatter,
-	)
+	writer := newSyncWriter(config.IOWriter)
+	var fallbackWriter io.Writer
+	if config.FallbackWriter != nil {
+		fallbackWriter = newSyncWriter(config.FallbackWriter)
+	}
+
+	if config.Handler == nil {
+		config.Handler = &JSONHandler{
+			logger: newJSONLogger(writer, fallbackWriter),
+		}
+	}
 
 	l := &MicroLogger{
-		logger: kitLogger,
+		handler:      config.Handler,
+		timestamp:    config.TimestampFormatter,
+		writer:       writer,
+		errorHandler: config.ErrorHandler,
+		stats:        &stats{},
+		exit:         config.Exit,
+		level:        level,
+		levels:       config.Levels,
+		errorLevels:  errorLevels,
+		caller:       caller,
+		customCaller: config.Caller,
+		stack:        stack,
+		processors:   append([]Processor{}, config.Processors...),
+		keyValsMode:  config.KeyValsMode,
+		keyValsHook:  config.KeyValsHook,
+		logrLevel:    config.LogrLevel,
 	}
 
 	return l, nil
@@ -59,67 +194,332 @@ func (l *MicroLogger) Debug(ctx context.Context, message string) {
 		"message", message,
 	}
 
-	l.log(keyValsWithMeta(ctx, kvs))
+	l.log(ctx, l.keyValsWithMeta(ctx, kvs))
 }
 
 func (l *MicroLogger) Debugf(ctx context.Context, format string, params ...interface{}) {
+	if !l.Enabled(ctx, "debug") {
+		return
+	}
 	l.Debug(ctx, fmt.Sprintf(format, params...))
 }
 
 func (l *MicroLogger) Error(ctx context.Context, err error, message string) {
-	var kvs []interface{}
-	if err != nil {
-		kvs = []interface{}{
-			"level", "error",
-			"message", message,
-			"stack", microerror.JSON(err),
-		}
-	} else {
-		kvs = []interface{}{
-			"level", "error",
-			"message", message,
-		}
-	}
+	level, skipStack := l.errorLevel(err)
+	kvs := errorKeyVals(level, message, err, skipStack)
 
-	l.log(keyValsWithMeta(ctx, kvs))
+	l.log(ctx, l.keyValsWithMeta(ctx, kvs))
 }
 
 func (l *MicroLogger) Errorf(ctx context.Context, err error, format string, params ...interface{}) {
 	l.Error(ctx, err, fmt.Sprintf(format, params...))
 }
 
+// Fatal takes an error and a message and writes them in fatal level, just
+// like Error does in error level. Then it flushes the IOWriter and calls the
+// configured exit function with exit code 1.
+func (l *MicroLogger) Fatal(ctx context.Context, err error, message string) {
+	kvs := errorKeyVals("fatal", message, err, false)
+
+	l.log(ctx, l.keyValsWithMeta(ctx, kvs))
+	l.flushAndExit(1)
+}
+
+// flushAndExit flushes the IOWriter and calls the configured exit function
+// with the given exit code.
+func (l *MicroLogger) flushAndExit(code int) {
+	l.flush()
+	l.exit(code)
+}
+
+// Fatalf is like Fatal but takes a format string and parameters.
+func (l *MicroLogger) Fatalf(ctx context.Context, err error, format string, params ...interface{}) {
+	l.Fatal(ctx, err, fmt.Sprintf(format, params...))
+}
+
 func (l *MicroLogger) Log(keyVals ...interface{}) {
-	l.log(processStack(keyVals))
+	l.log(context.Background(), l.stack.processKeyVals(l.checkKeyVals(keyVals)))
 }
 
 func (l *MicroLogger) LogCtx(ctx context.Context, keyVals ...interface{}) {
-	l.log(keyValsWithMeta(ctx, keyVals))
+	l.log(ctx, l.keyValsWithMeta(ctx, l.checkKeyVals(keyVals)))
+}
+
+// LogFields writes a record of the given level and message with the given
+// typed fields. See Logger.LogFields.
+//
+// Fields of strings, integers and durations are kept typed until they are
+// encoded, so that they are not allocated on the heap.
+func (l *MicroLogger) LogFields(ctx context.Context, level string, message string, fields ...Field) {
+	keyVals := []interface{}{
+		KeyLevel, level,
+		"message", message,
+	}
+
+	l.logFields(ctx, keyVals, l.stack.processFields(fields), metaFields(ctx))
 }
 
 func (l *MicroLogger) deepCopy() *MicroLogger {
 	return &MicroLogger{
-		info:      l.info,
-		logger:    l.logger,
-		verbosity: l.verbosity,
-		names:     l.names[:],
+		info:         l.info,
+		handler:      l.handler,
+		timestamp:    l.timestamp,
+		keyVals:      l.keyVals,
+		processors:   l.processors,
+		writer:       l.writer,
+		errorHandler: l.errorHandler,
+		stats:        l.stats,
+		exit:         l.exit,
+		level:        l.level,
+		levels:       l.levels,
+		errorLevels:  l.errorLevels,
+		caller:       l.caller,
+		callerSkip:   l.callerSkip,
+		customCaller: l.customCaller,
+		stack:        l.stack,
+		keyValsMode:  l.keyValsMode,
+		keyValsHook:  l.keyValsHook,
+		logrLevel:    l.logrLevel,
+		verbosity:    l.verbosity,
+		name:         l.name,
 	}
 }
 
+// Named returns a copy of the logger with the given name appended to its
+// dotted name. See Logger.Named.
+func (l *MicroLogger) Named(name string) Logger {
+	loggerCopy := l.deepCopy()
+	loggerCopy.name = joinName(l.name, name)
+	return loggerCopy
+}
+
 func (l *MicroLogger) With(keyVals ...interface{}) Logger {
 	loggerCopy := l.deepCopy()
-	loggerCopy.logger = kitlog.With(loggerCopy.logger, keyVals...)
+	loggerCopy.appendKeyVals(l.stack.processKeyVals(l.checkKeyVals(keyVals)))
 	return loggerCopy
 }
 
-func (l *MicroLogger) log(keyVals []interface{}) {
-	err := l.logger.Log(keyVals...)
+// appendKeyVals appends the given keyVals to the contextual keyVals of the
+// logger, which precede the keyVals of every record. Lazy and kitlog.Valuer
+// values are evaluated for every record.
+func (l *MicroLogger) appendKeyVals(keyVals []interface{}) {
+	var kvs []interface{}
+	{
+		kvs = append(kvs, l.keyVals...)
+		kvs = append(kvs, keyVals...)
+	}
+
+	l.keyVals = kvs
+}
+
+// flush flushes the handler in case it implements Flusher. The default
+// JSONHandler flushes the IOWriter and the FallbackWriter.
+func (l *MicroLogger) flush() {
+	f, ok := l.handler.(Flusher)
+	if !ok {
+		return
+	}
+
+	err := f.Flush()
+	if IsWriteFailed(err) {
+		l.handleError(microerror.Mask(err), nil)
+	} else if err != nil {
+		l.handleError(microerror.Maskf(writeFailedError, "flush: %s", err.Error()), nil)
+	}
+}
+
+func (l *MicroLogger) log(ctx context.Context, keyVals []interface{}) {
+	l.logFields(ctx, keyVals, nil, nil)
+}
+
+// logFields writes the record of the given keyVals followed by the given
+// fields and metaFields. See Record for the order of fields.
+func (l *MicroLogger) logFields(ctx context.Context, keyVals []interface{}, fields []Field, metaFields []Field) {
+	if !l.enabled(ctx, keyVals) {
+		return
+	}
+
+	now := time.Now()
+
+	kvs := make([]interface{}, 0, 10+len(l.keyVals)+len(keyVals))
+	{
+		if l.customCaller != nil {
+			kvs = append(kvs, KeyCaller, l.customCaller())
+		}
+		kvs = append(kvs, "time", l.timestamp())
+		kvs = append(kvs, l.keyVals...)
+		if l.customCaller == nil {
+			kvs = append(kvs, l.caller.resolve(l.callerSkip)...)
+		}
+		if l.name != "" {
+			kvs = append(kvs, KeyName, l.name)
+		}
+		kvs = append(kvs, keyVals...)
+	}
+	kvs = l.stack.evaluateLazy(kvs)
+	fields = l.stack.evaluateLazyFields(fields)
+
+	// Without processors records written by the JSONHandler are encoded
+	// directly, without creating a Record. The Record is only created for
+	// the ErrorHandler in case of failures.
+	if h, ok := l.handler.(*JSONHandler); ok && len(l.processors) == 0 {
+		err := h.logger.logKeyVals(kvs, fields, metaFields)
+		if err != nil {
+			l.handleError(err, newRecordFields(ctx, now, kvs, fields, metaFields).KeyVals())
+		}
+		return
+	}
+
+	r := newRecordFields(ctx, now, kvs, fields, metaFields)
+	if len(l.processors) == 0 {
+		l.handle(r)
+		return
+	}
+
+	for _, r := range processRecords(l.processors, r) {
+		l.handle(r)
+	}
+}
+
+// newRecordFields creates a record from the given keyVals followed by the
+// given fields and metaFields.
+func newRecordFields(ctx context.Context, t time.Time, keyVals []interface{}, fields []Field, metaFields []Field) *Record {
+	r := newRecord(ctx, t, keyVals, len(fields)+len(metaFields))
+	r.Fields = append(r.Fields, fields...)
+	r.Fields = append(r.Fields, metaFields...)
+
+	return r
+}
+
+// errorClassifier is implemented by loggers which classify errors using
+// Config.ErrorLevels, so that wrappers making decisions based on the level,
+// like the activation logger, apply them as well.
+type errorClassifier interface {
+	errorLevel(err error) (string, bool)
+}
+
+// errorLevel returns the level and whether to skip the stack for the given
+// error according to Config.ErrorLevels.
+func (l *MicroLogger) errorLevel(err error) (string, bool) {
+	return classifyError(l.errorLevels, err)
+}
+
+// callerResolver is implemented by loggers which render the caller of the
+// current logging call, so that wrappers deferring records, like the flight
+// recorder, preserve it in the configured format.
+type callerResolver interface {
+	callerKeyVals() []interface{}
+}
+
+// callerKeyVals returns the caller keyVals of the current logging call as
+// configured by Config.Caller and Config.CallerFormat.
+func (l *MicroLogger) callerKeyVals() []interface{} {
+	if l.customCaller != nil {
+		return []interface{}{KeyCaller, l.customCaller()}
+	}
+
+	return l.caller.resolve(l.callerSkip)
+}
+
+// timeResolver is implemented by loggers which render the time of the
+// current logging call, so that wrappers deferring records, like the flight
+// recorder, preserve it in the configured format.
+type timeResolver interface {
+	timeKeyVals() []interface{}
+}
+
+// timeKeyVals returns the time keyVals of the current logging call as
+// configured by Config.TimestampFormatter.
+func (l *MicroLogger) timeKeyVals() []interface{} {
+	return []interface{}{"time", l.timestamp()}
+}
+
+func (l *MicroLogger) handle(r *Record) {
+	err := l.handler.Handle(r)
 	if err != nil {
-		log.Printf("failed to log with error: %#q, keyVals = %v", err.Error(), keyVals)
+		l.handleError(err, r.KeyVals())
+	}
+}
+
+// SetOutput redirects the output of the logger and of all loggers sharing it,
+// i.e. the loggers it was derived from and derived loggers, e.g. created using
+// With. The previous IOWriter is flushed before. A nil writer resets the
+// output to DefaultIOWriter. SetOutput is safe to call concurrently with
+// logging calls.
+func (l *MicroLogger) SetOutput(w io.Writer) {
+	if w == nil {
+		w = DefaultIOWriter
+	}
+
+	err := l.writer.Swap(w)
+	if err != nil {
+		l.handleError(microerror.Maskf(writeFailedError, "flush: %s", err.Error()), nil)
+	}
+
+	// Custom handlers do not write to the IOWriter, but may buffer records
+	// all the same.
+	if h, ok := l.handler.(*JSONHandler); !ok || h.logger.w != l.writer {
+		l.flush()
 	}
 }
 
-func keyValsWithMeta(ctx context.Context, keyVals []interface{}) []interface{} {
-	keyVals = processStack(keyVals)
+func (l *MicroLogger) handleError(err error, keyVals []interface{}) {
+	l.stats.count(err)
+	l.errorHandler(err, keyVals)
+}
+
+// Stats returns the failure counters of the logger. See Stats.
+func (l *MicroLogger) Stats() Stats {
+	return l.stats.snapshot()
+}
+
+// Enabled returns whether records of the given level are written by calls
+// made with ctx. See Logger.Enabled.
+func (l *MicroLogger) Enabled(ctx context.Context, level string) bool {
+	return l.enabled(ctx, []interface{}{KeyLevel, level})
+}
+
+// enabled returns whether the record described by keyVals passes the
+// configured level, or the level configured for the name of the logger.
+func (l *MicroLogger) enabled(ctx context.Context, keyVals []interface{}) bool {
+	minLevel := l.level
+	if l.levels != nil && l.name != "" {
+		if id, ok := l.levels.level(l.name); ok {
+			minLevel = id
+		}
+	}
+
+	if minLevel == 0 || IsDebug(ctx) {
+		return true
+	}
+
+	v, _ := valueFor(keyVals, KeyLevel)
+	s, _ := v.(string)
+	level, ok := levelMapping[s]
+	if !ok {
+		return true
+	}
+
+	return level >= minLevel
+}
+
+func errorKeyVals(level string, message string, err error, skipStack bool) []interface{} {
+	if err == nil || skipStack {
+		return []interface{}{
+			"level", level,
+			"message", message,
+		}
+	}
+
+	return []interface{}{
+		"level", level,
+		"message", message,
+		"stack", microerror.JSON(err),
+	}
+}
+
+func (l *MicroLogger) keyValsWithMeta(ctx context.Context, keyVals []interface{}) []interface{} {
+	keyVals = l.stack.processKeyVals(keyVals)
 	meta, ok := loggermeta.FromContext(ctx)
 	if !ok {
 		return keyVals
@@ -138,13 +538,36 @@ func keyValsWithMeta(ctx context.Context, keyVals []interface{}) []interface{} {
 	return kvs
 }
 
-func (l *MicroLogger) WithIncreasedCallerDepth() Logger {
-	return &MicroLogger{
-		logger: kitlog.With(l.logger, "caller", newCallerFunc(1)),
+// metaFields returns the loggermeta of ctx, if any, as fields.
+func metaFields(ctx context.Context) []Field {
+	meta, ok := loggermeta.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	fields := make([]Field, 0, len(meta.KeyVals))
+	for k, v := range meta.KeyVals {
+		fields = append(fields, String(k, v))
 	}
+
+	return fields
 }
 
-func processStack(keyVals []interface{}) []interface{} {
+// WithCallerSkip returns a copy of the logger which skips n frames in
+// addition when resolving the caller. All other state, including key-value
+// pairs added using With, is kept. Skips of nested calls add up, so that
+// every wrapper can account for its own frames.
+func (l *MicroLogger) WithCallerSkip(n int) Logger {
+	loggerCopy := l.deepCopy()
+	loggerCopy.callerSkip += n
+	return loggerCopy
+}
+
+func (l *MicroLogger) WithIncreasedCallerDepth() Logger {
+	return l.WithCallerSkip(1)
+}
+
+func (f stackFormatter) processStack(keyVals []interface{}) []interface{} {
 	for i := 1; i < len(keyVals); i += 2 {
 		k := keyVals[i-1]
 		v := keyVals[i]
@@ -176,6 +599,9 @@ func processStack(keyVals []interface{}) []interface{} {
 		// If the found value is a JSON then make a copy of keyVals to
 		// not mutate the original one and store the value as a map to
 		// be rendered as a JSON object. Then return it.
+		if f.enabled() {
+			f.formatStackObject(m)
+		}
 		keyValsCopy := append([]interface{}{}, keyVals...)
 		keyValsCopy[i] = m
 
@@ -192,3 +618,15 @@ func (l *MicroLogger) AsSink(verbosity int) logr.LogSink {
 		MicroLogger: loggerCopy,
 	}
 }
+
+// joinName appends name to the dotted parent name.
+func joinName(parent string, name string) string {
+	if parent == "" {
+		return name
+	}
+	if name == "" {
+		return parent
+	}
+
+	return parent + "." + name
+}
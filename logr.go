@@ -2,60 +2,145 @@ package micrologger
 
 import (
 	"context"
-	"strings"
+	"fmt"
 
-	kitlog "github.com/go-kit/log"
 	"github.com/go-logr/logr"
-	"github.com/go-stack/stack"
+)
+
+var (
+	_ logr.CallDepthLogSink       = &LogrSink{}
+	_ logr.CallStackHelperLogSink = &LogrSink{}
+	_ logr.SlogSink               = &LogrSink{}
 )
 
 type LogrSink struct {
 	*MicroLogger
+
+	// group is the dotted prefix of keys added by the slog bridge, see
+	// WithGroup.
+	group string
 }
 
 func (l *LogrSink) Init(info logr.RuntimeInfo) {
 	l.info = info
 }
 
+// Enabled returns whether info records of the given V-level are written, i.e.
+// whether the V-level is within the verbosity of the sink and the level
+// LogrLevel maps it to passes Config.Level and Config.Levels.
 func (l *LogrSink) Enabled(level int) bool {
-	return l.verbosity >= level
+	if l.verbosity < level {
+		return false
+	}
+
+	return l.enabled(context.Background(), []interface{}{KeyLevel, l.logrLevel(level)})
 }
 
+// Info writes the record in the level LogrLevel maps the given V-level to,
+// carrying the V-level under the "verbosity" key. Records of V-levels above
+// the verbosity of the sink are dropped.
 func (l *LogrSink) Info(level int, msg string, keysAndValues ...interface{}) {
-	if l.verbosity < level {
+	if !l.Enabled(level) {
 		return
 	}
-	keysAndValues = append(keysAndValues, l.getValues("debug")...)
-	l.With(keysAndValues...).Log("message", msg)
+
+	var kvs []interface{}
+	{
+		kvs = append(kvs, l.getValues(l.logrLevel(level))...)
+		kvs = append(kvs, KeyVerbosity, level)
+		kvs = append(kvs, "message", msg)
+	}
+
+	l.With(marshalValues(keysAndValues)...).Log(kvs...)
 }
 
+// Error writes the record in error level regardless of the verbosity of the
+// sink.
 func (l *LogrSink) Error(err error, msg string, keysAndValues ...interface{}) {
-	if l.verbosity < 1 {
-		return
-	}
+	keysAndValues = marshalValues(l.checkKeyVals(keysAndValues))
 	keysAndValues = append(keysAndValues, l.getValues("error")...)
-	l.With(keysAndValues...).Errorf(context.Background(), err, msg)
+
+	// The key-value pairs are checked once above, so that Config.KeyValsHook
+	// is not called twice, which With would do.
+	loggerCopy := l.MicroLogger.deepCopy()
+	loggerCopy.appendKeyVals(l.stack.processKeyVals(keysAndValues))
+	loggerCopy.Errorf(context.Background(), err, msg)
 }
 
 func (l *LogrSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
-	loggerCopy := l.deepCopy()
-	loggerCopy.logger = kitlog.With(loggerCopy.logger, processStack(keysAndValues)...)
-	return loggerCopy.AsSink(loggerCopy.verbosity)
+	sinkCopy := l.deepCopy()
+	sinkCopy.appendKeyVals(l.stack.processKeyVals(marshalValues(l.checkKeyVals(keysAndValues))))
+	return sinkCopy
+}
+
+// WithCallDepth implements logr.CallDepthLogSink. See
+// MicroLogger.WithCallerSkip.
+func (l *LogrSink) WithCallDepth(depth int) logr.LogSink {
+	sinkCopy := l.deepCopy()
+	sinkCopy.callerSkip += depth
+	return sinkCopy
+}
+
+// GetCallStackHelper implements logr.CallStackHelperLogSink. The returned
+// function does nothing, because logr.Logger.WithCallStackHelper already
+// skips the frame of the helper using WithCallDepth. Use Helper to mark
+// functions as helpers for all loggers.
+func (l *LogrSink) GetCallStackHelper() func() {
+	return func() {}
 }
 
 func (l *LogrSink) WithName(name string) logr.LogSink {
-	loggerCopy := l.deepCopy()
-	loggerCopy.names = append(loggerCopy.names[:], name)
-	return loggerCopy.AsSink(l.verbosity)
+	sinkCopy := l.deepCopy()
+	sinkCopy.name = joinName(l.name, name)
+	return sinkCopy
+}
+
+func (l *LogrSink) deepCopy() *LogrSink {
+	return &LogrSink{
+		MicroLogger: l.MicroLogger.deepCopy(),
+		group:       l.group,
+	}
 }
 
 func (l *LogrSink) getValues(level string) []interface{} {
 	return []interface{}{
 		"level",
 		level,
-		"name",
-		strings.Join(l.names, "."),
-		"caller",
-		stack.Caller(l.info.CallDepth),
+		KeyName,
+		l.name,
+	}
+}
+
+// marshalValues replaces values implementing logr.Marshaler with the result of
+// their MarshalLog method. The given keysAndValues are not mutated.
+func marshalValues(keysAndValues []interface{}) []interface{} {
+	var kvs []interface{}
+	for i := 1; i < len(keysAndValues); i += 2 {
+		m, ok := keysAndValues[i].(logr.Marshaler)
+		if !ok {
+			continue
+		}
+		if kvs == nil {
+			kvs = append([]interface{}{}, keysAndValues...)
+		}
+		kvs[i] = marshalLog(m)
+	}
+
+	if kvs == nil {
+		return keysAndValues
 	}
+
+	return kvs
+}
+
+// marshalLog calls MarshalLog of the given value. Panics, e.g. caused by nil
+// pointer receivers, are rendered in place of the value.
+func marshalLog(m logr.Marshaler) (v interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			v = fmt.Sprintf("PANIC=%v", r)
+		}
+	}()
+
+	return m.MarshalLog()
 }
@@ -0,0 +1,176 @@
+package micrologger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_LogrSink_levels(t *testing.T) {
+	infoLevel := func(verbosity int) string {
+		if verbosity == 0 {
+			return "info"
+		}
+		return "debug"
+	}
+
+	testCases := []struct {
+		name      string
+		config    Config
+		verbosity int
+		log       func(l logr.Logger)
+		expected  []map[string]interface{}
+	}{
+		{
+			name:      "case 0: info records carry their verbosity in debug level by default",
+			verbosity: 1,
+			log: func(l logr.Logger) {
+				l.Info("test 0")
+				l.V(1).Info("test 1")
+				l.V(2).Info("test 2")
+			},
+			expected: []map[string]interface{}{
+				{"level": "debug", "message": "test 0", "name": "", "verbosity": float64(0)},
+				{"level": "debug", "message": "test 1", "name": "", "verbosity": float64(1)},
+			},
+		},
+		{
+			name:      "case 1: V-levels mapped to info and debug",
+			config:    Config{LogrLevel: infoLevel},
+			verbosity: 1,
+			log: func(l logr.Logger) {
+				l.Info("test 0")
+				l.V(1).Info("test 1")
+			},
+			expected: []map[string]interface{}{
+				{"level": "info", "message": "test 0", "name": "", "verbosity": float64(0)},
+				{"level": "debug", "message": "test 1", "name": "", "verbosity": float64(1)},
+			},
+		},
+		{
+			name:      "case 2: errors are written regardless of the verbosity",
+			verbosity: 0,
+			log: func(l logr.Logger) {
+				l.Error(errors.New("test error"), "test 0")
+				l.V(2).Error(nil, "test 1")
+			},
+			expected: []map[string]interface{}{
+				{"level": "error", "message": "test 0", "name": "", "stack": map[string]interface{}{"annotation": "test error", "kind": "unknown"}},
+				{"level": "error", "message": "test 1", "name": ""},
+			},
+		},
+		{
+			name:      "case 3: info records are filtered by Config.Level",
+			config:    Config{Level: "info", LogrLevel: infoLevel},
+			verbosity: 1,
+			log: func(l logr.Logger) {
+				l.Info("test 0")
+				l.V(1).Info("test 1")
+			},
+			expected: []map[string]interface{}{
+				{"level": "info", "message": "test 0", "name": "", "verbosity": float64(0)},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &bytes.Buffer{}
+
+			config := tc.config
+			config.IOWriter = w
+			logger, err := New(config)
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			tc.log(logr.New(logger.AsSink(tc.verbosity)))
+
+			var entries []map[string]interface{}
+			d := json.NewDecoder(w)
+			for d.More() {
+				var entry map[string]interface{}
+				err = d.Decode(&entry)
+				if err != nil {
+					t.Fatalf("err = %v, want %v", err, nil)
+				}
+				delete(entry, KeyCaller)
+				delete(entry, "time")
+				entries = append(entries, entry)
+			}
+
+			if !cmp.Equal(entries, tc.expected) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expected, entries))
+			}
+		})
+	}
+}
+
+func Test_LogrSink_Enabled(t *testing.T) {
+	infoLevel := func(verbosity int) string {
+		if verbosity == 0 {
+			return "info"
+		}
+		return "debug"
+	}
+
+	levels, err := NewLevels(LevelsConfig{Levels: map[string]string{"verbose": "debug"}})
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	testCases := []struct {
+		name      string
+		config    Config
+		logrName  string
+		verbosity int
+		expected  []bool
+	}{
+		{
+			name:      "case 0: V-levels within the verbosity",
+			verbosity: 1,
+			expected:  []bool{true, true, false},
+		},
+		{
+			name:      "case 1: V-levels mapped to levels below Config.Level",
+			config:    Config{Level: "info", LogrLevel: infoLevel},
+			verbosity: 2,
+			expected:  []bool{true, false, false},
+		},
+		{
+			name:      "case 2: V-levels mapped to levels of Config.Levels",
+			config:    Config{Level: "info", Levels: levels, LogrLevel: infoLevel},
+			logrName:  "verbose",
+			verbosity: 1,
+			expected:  []bool{true, true, false},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			logger, err := New(tc.config)
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			sink := logger.AsSink(tc.verbosity)
+			if tc.logrName != "" {
+				sink = sink.WithName(tc.logrName)
+			}
+
+			var actual []bool
+			for v := range tc.expected {
+				actual = append(actual, sink.Enabled(v))
+			}
+
+			if !cmp.Equal(actual, tc.expected) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expected, actual))
+			}
+		})
+	}
+}
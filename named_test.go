@@ -0,0 +1,87 @@
+package micrologger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Logger_Named(t *testing.T) {
+	testCases := []struct {
+		name     string
+		log      func(t *testing.T, l *MicroLogger)
+		expected []string
+	}{
+		{
+			name: "case 0: unnamed logger writes no name",
+			log: func(t *testing.T, l *MicroLogger) {
+				l.Debug(context.Background(), "test")
+			},
+			expected: []string{""},
+		},
+		{
+			name: "case 1: nested names are dotted",
+			log: func(t *testing.T, l *MicroLogger) {
+				l.Named("resource").With("foo", "bar").Named("endpoint").Debug(context.Background(), "test")
+			},
+			expected: []string{"resource.endpoint"},
+		},
+		{
+			name: "case 2: logr names continue the dotted name",
+			log: func(t *testing.T, l *MicroLogger) {
+				logr.New(l.Named("resource").(*MicroLogger).AsSink(0)).WithName("endpoint").Info("test")
+			},
+			expected: []string{"resource.endpoint"},
+		},
+		{
+			name: "case 3: activation logger filters by name",
+			log: func(t *testing.T, l *MicroLogger) {
+				a, err := NewActivation(ActivationLoggerConfig{
+					Underlying:  l,
+					Activations: map[string]interface{}{KeyName: "resource.endpoint"},
+				})
+				if err != nil {
+					t.Fatalf("err = %v, want %v", err, nil)
+				}
+
+				a.Named("resource").Debug(context.Background(), "dropped")
+				a.Named("resource").Named("endpoint").Debug(context.Background(), "test")
+			},
+			expected: []string{"resource.endpoint"},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &bytes.Buffer{}
+
+			logger, err := New(Config{IOWriter: w})
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			tc.log(t, logger)
+
+			var names []string
+			d := json.NewDecoder(w)
+			for d.More() {
+				var entry map[string]interface{}
+				err = d.Decode(&entry)
+				if err != nil {
+					t.Fatalf("err = %v, want %v", err, nil)
+				}
+				name, _ := entry[KeyName].(string)
+				names = append(names, name)
+			}
+
+			if !cmp.Equal(names, tc.expected) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expected, names))
+			}
+		})
+	}
+}
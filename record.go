@@ -0,0 +1,141 @@
+package micrologger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kitlog "github.com/go-kit/log"
+)
+
+// Record is a log record as seen by processors and handlers. Fields are
+// ordered like the key-value pairs of the record: the formatted "time",
+// contextual key-value pairs added using With, the caller when split into
+// several keys, the logger name, the key-value pairs of the logging call and
+// finally those of loggermeta. Later fields win over earlier fields of the
+// same key when the record is written.
+type Record struct {
+	Context context.Context
+	// Time is the time the record was created. The time written by the
+	// JSONHandler is the "time" field rendered by Config.TimestampFormatter.
+	Time    time.Time
+	Level   string
+	Message string
+	// Caller is the value of the "caller" key, e.g.
+	// "github.com/giantswarm/micrologger/logger.go:42".
+	Caller string
+	Fields []Field
+}
+
+// Processor processes records before they are encoded, e.g. to redact,
+// enrich, sample or route them. It may modify the given record and returns the
+// records to be written: none to drop the record, the record itself or
+// several, e.g. duplicates created using Clone.
+type Processor func(r *Record) []*Record
+
+// Clone returns a copy of the record which can be modified independently.
+func (r *Record) Clone() *Record {
+	c := *r
+	c.Fields = append([]Field(nil), r.Fields...)
+	return &c
+}
+
+// Value returns the value of the last field with the given key.
+func (r *Record) Value(key string) (interface{}, bool) {
+	for i := len(r.Fields) - 1; i >= 0; i-- {
+		if r.Fields[i].Key == key {
+			return r.Fields[i].Value(), true
+		}
+	}
+
+	return nil, false
+}
+
+// newRecord creates a record from the given keyVals. The last "level",
+// "message" and "caller" string values are moved to Level, Message and Caller.
+// Capacity for extra fields is reserved.
+func newRecord(ctx context.Context, t time.Time, keyVals []interface{}, extra int) *Record {
+	r := &Record{
+		Context: ctx,
+		Time:    t,
+		Fields:  make([]Field, 0, len(keyVals)/2+extra),
+	}
+
+	for i := 0; i < len(keyVals); i += 2 {
+		key := keyString(keyVals[i])
+		var value interface{} = kitlog.ErrMissingValue
+		if i+1 < len(keyVals) {
+			value = keyVals[i+1]
+		}
+
+		switch key {
+		case KeyLevel:
+			if s, ok := value.(string); ok {
+				r.Level = s
+				continue
+			}
+		case "message":
+			if s, ok := value.(string); ok {
+				r.Message = s
+				continue
+			}
+		case KeyCaller:
+			if s, ok := value.(string); ok {
+				r.Caller = s
+				continue
+			}
+		}
+
+		r.Fields = append(r.Fields, Object(key, value))
+	}
+
+	return r
+}
+
+// KeyVals returns the key-value pairs of the record to be written, e.g. by
+// handlers adapting other logging libraries. Empty callers, levels and
+// messages are omitted.
+func (r *Record) KeyVals() []interface{} {
+	keyVals := make([]interface{}, 0, 6+2*len(r.Fields))
+	if r.Caller != "" {
+		keyVals = append(keyVals, KeyCaller, r.Caller)
+	}
+	for _, f := range r.Fields {
+		keyVals = append(keyVals, f.Key, f.Value())
+	}
+	if r.Level != "" {
+		keyVals = append(keyVals, KeyLevel, r.Level)
+	}
+	if r.Message != "" {
+		keyVals = append(keyVals, "message", r.Message)
+	}
+
+	return keyVals
+}
+
+// processRecords runs the given processors in order on the given record and
+// the records returned by previous processors.
+func processRecords(processors []Processor, r *Record) []*Record {
+	records := []*Record{r}
+	for _, p := range processors {
+		var next []*Record
+		for _, r := range records {
+			next = append(next, p(r)...)
+		}
+		records = next
+	}
+
+	return records
+}
+
+// keyString converts the given key to a string like go-kit/log does.
+func keyString(k interface{}) string {
+	switch x := k.(type) {
+	case string:
+		return x
+	case fmt.Stringer:
+		return safeString(x)
+	default:
+		return fmt.Sprint(x)
+	}
+}
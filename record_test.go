@@ -0,0 +1,117 @@
+package micrologger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/giantswarm/micrologger/loggermeta"
+)
+
+func Test_MicroLogger_Processors(t *testing.T) {
+	testCases := []struct {
+		name       string
+		processors []Processor
+		expected   []map[string]interface{}
+	}{
+		{
+			name: "case 0: no processors",
+			expected: []map[string]interface{}{
+				{"level": "debug", "message": "test", "password": "secret", "request": "1", "with": "value"},
+			},
+		},
+		{
+			name: "case 1: redact and enrich",
+			processors: []Processor{
+				func(r *Record) []*Record {
+					for i, f := range r.Fields {
+						if f.Key == "password" {
+							r.Fields[i] = String("password", "REDACTED")
+						}
+					}
+					return []*Record{r}
+				},
+				func(r *Record) []*Record {
+					v, _ := r.Value("with")
+					r.Fields = append(r.Fields, Object("seen", v))
+					r.Level = "info"
+					return []*Record{r}
+				},
+			},
+			expected: []map[string]interface{}{
+				{"level": "info", "message": "test", "password": "REDACTED", "request": "1", "seen": "value", "with": "value"},
+			},
+		},
+		{
+			name: "case 2: drop",
+			processors: []Processor{
+				func(r *Record) []*Record {
+					return nil
+				},
+			},
+		},
+		{
+			name: "case 3: duplicate",
+			processors: []Processor{
+				func(r *Record) []*Record {
+					c := r.Clone()
+					c.Message = "copy"
+					c.Fields = c.Fields[:0]
+					return []*Record{r, c}
+				},
+				func(r *Record) []*Record {
+					meta, _ := loggermeta.FromContext(r.Context)
+					r.Fields = append(r.Fields, String("ctx", meta.KeyVals["request"]))
+					return []*Record{r}
+				},
+			},
+			expected: []map[string]interface{}{
+				{"ctx": "1", "level": "debug", "message": "test", "password": "secret", "request": "1", "with": "value"},
+				{"ctx": "1", "level": "debug", "message": "copy"},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &bytes.Buffer{}
+
+			logger, err := New(Config{
+				Caller:             func() interface{} { return "--REPLACED--" },
+				IOWriter:           w,
+				TimestampFormatter: func() interface{} { return "--REPLACED--" },
+				Processors:         tc.processors,
+			})
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			meta := loggermeta.New()
+			meta.KeyVals["request"] = "1"
+			ctx := loggermeta.NewContext(context.Background(), meta)
+
+			logger.With("with", "value").LogCtx(ctx, "level", "debug", "message", "test", "password", "secret")
+
+			var entries []map[string]interface{}
+			d := json.NewDecoder(w)
+			for d.More() {
+				var entry map[string]interface{}
+				err = d.Decode(&entry)
+				if err != nil {
+					t.Fatalf("err = %v, want %v", err, nil)
+				}
+				delete(entry, KeyCaller)
+				delete(entry, "time")
+				entries = append(entries, entry)
+			}
+
+			if !cmp.Equal(entries, tc.expected) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expected, entries))
+			}
+		})
+	}
+}
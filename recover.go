@@ -0,0 +1,129 @@
+package micrologger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/giantswarm/microerror"
+)
+
+const (
+	KeyPanic = "panic"
+)
+
+const (
+	// RecoverRepanic logs recovered panics and panics again with the same
+	// value. This is the default.
+	RecoverRepanic RecoverMode = iota
+	// RecoverExit logs recovered panics and exits the process.
+	RecoverExit
+	// RecoverSwallow logs recovered panics and continues execution.
+	RecoverSwallow
+)
+
+// exit is used by RecoverExit for loggers not carrying an exit function, see
+// exiter. It is overwritten in tests.
+var exit = os.Exit
+
+// RecoverMode defines what Recover does after logging a recovered panic.
+type RecoverMode int
+
+type RecoverOptions struct {
+	// Mode defines what happens after the panic is logged. Defaults to
+	// RecoverRepanic.
+	Mode RecoverMode
+	// ExitCode is the code the process exits with in RecoverExit mode.
+	// Defaults to 2, the exit code of unrecovered panics.
+	ExitCode int
+}
+
+// exiter is implemented by loggers which flush their output and exit using
+// Config.Exit, so that RecoverExit behaves like Fatal.
+type exiter interface {
+	flushAndExit(code int)
+}
+
+// Recover recovers panics and logs them in error level together with the
+// loggermeta of ctx. The panic value and the stack of the panicking goroutine
+// are written in the format of microerror under the "stack" key, alongside
+// "panic": true. The caller is the panicking function. Recover must be
+// deferred directly, e.g.
+//
+//	defer micrologger.Recover(ctx, logger, micrologger.RecoverOptions{})
+//
+// In RecoverExit mode the output of the logger is flushed and the process is
+// exited using Config.Exit, just like Fatal does.
+func Recover(ctx context.Context, logger Logger, opts RecoverOptions) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	logger.LogCtx(ctx,
+		"level", "error",
+		"message", "recovered panic",
+		KeyPanic, true,
+		"stack", PanicStack(v),
+	)
+
+	switch opts.Mode {
+	case RecoverExit:
+		code := opts.ExitCode
+		if code == 0 {
+			code = 2
+		}
+		if e, ok := logger.(exiter); ok {
+			e.flushAndExit(code)
+		} else {
+			exit(code)
+		}
+	case RecoverSwallow:
+	default:
+		panic(v)
+	}
+}
+
+// Go runs fn in a new goroutine which recovers and logs panics of fn using
+// Recover with default options. Panics are therefore still fatal, but logged
+// before crashing the process.
+func Go(ctx context.Context, logger Logger, fn func(ctx context.Context)) {
+	go func() {
+		defer Recover(ctx, logger, RecoverOptions{})
+		fn(ctx)
+	}()
+}
+
+// PanicStack renders the given panic value and the stack of the panicking
+// goroutine in the JSON format of microerror. It must be called directly by
+// the deferred function recovering the panic.
+func PanicStack(v interface{}) string {
+	pcs := make([]uintptr, 64)
+	// Skip runtime.Callers, PanicStack, the recovering function and
+	// runtime.gopanic.
+	n := runtime.Callers(4, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	o := microerror.JSONError{
+		Error: &microerror.Error{
+			Kind: "panic",
+		},
+		Annotation: fmt.Sprintf("%v", v),
+	}
+	for {
+		f, more := frames.Next()
+		o.Stack = append(o.Stack, microerror.StackEntry{File: f.File, Line: f.Line})
+		if !more {
+			break
+		}
+	}
+
+	bytes, err := json.Marshal(o)
+	if err != nil {
+		return microerror.JSON(err)
+	}
+
+	return string(bytes)
+}
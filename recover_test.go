@@ -0,0 +1,138 @@
+package micrologger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func Test_Recover(t *testing.T) {
+	testCases := []struct {
+		name             string
+		opts             RecoverOptions
+		expectedPanic    bool
+		expectedExitCode int
+	}{
+		{
+			name:          "case 0: re-panic by default",
+			opts:          RecoverOptions{},
+			expectedPanic: true,
+		},
+		{
+			name: "case 1: exit with default code",
+			opts: RecoverOptions{
+				Mode: RecoverExit,
+			},
+			expectedExitCode: 2,
+		},
+		{
+			name: "case 2: exit with given code",
+			opts: RecoverOptions{
+				Mode:     RecoverExit,
+				ExitCode: 3,
+			},
+			expectedExitCode: 3,
+		},
+		{
+			name: "case 3: swallow",
+			opts: RecoverOptions{
+				Mode: RecoverSwallow,
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &bytes.Buffer{}
+			b := bufio.NewWriter(w)
+
+			var exitCode int
+			var written []byte
+
+			logger, err := New(Config{
+				IOWriter: b,
+				Exit: func(code int) {
+					exitCode = code
+					written = append(written, w.Bytes()...)
+				},
+			})
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			var panicked bool
+			var panicLine int
+			func() {
+				defer func() {
+					panicked = recover() != nil
+				}()
+				defer Recover(context.Background(), logger, tc.opts)
+
+				panicLine = line() + 1
+				panic("test")
+			}()
+
+			if panicked != tc.expectedPanic {
+				t.Fatalf("panicked = %v, want %v", panicked, tc.expectedPanic)
+			}
+			if exitCode != tc.expectedExitCode {
+				t.Fatalf("exit code = %d, want %d", exitCode, tc.expectedExitCode)
+			}
+
+			// The output must be flushed before exiting.
+			if tc.expectedExitCode == 0 {
+				_ = b.Flush()
+				written = w.Bytes()
+			}
+
+			var entry map[string]interface{}
+			err = json.Unmarshal(written, &entry)
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			if entry[KeyPanic] != true {
+				t.Fatalf("panic = %v, want %v", entry[KeyPanic], true)
+			}
+			if entry["level"] != "error" {
+				t.Fatalf("level = %v, want %v", entry["level"], "error")
+			}
+			if caller := "recover_test.go:" + strconv.Itoa(panicLine); !strings.HasSuffix(entry[KeyCaller].(string), caller) {
+				t.Fatalf("caller = %v, want %v", entry[KeyCaller], caller)
+			}
+
+			stack := entry["stack"].(map[string]interface{})
+			if stack["annotation"] != "test" {
+				t.Fatalf("annotation = %v, want %v", stack["annotation"], "test")
+			}
+			file := stack["stack"].([]interface{})[0].(map[string]interface{})["file"].(string)
+			if !strings.HasSuffix(file, "/recover_test.go") {
+				t.Fatalf("file = %#q, want panicking file", file)
+			}
+		})
+	}
+}
+
+func Test_Go(t *testing.T) {
+	logger, err := New(Config{IOWriter: &bytes.Buffer{}})
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "foo")
+
+	done := make(chan interface{})
+	Go(ctx, logger, func(ctx context.Context) {
+		done <- ctx.Value(key{})
+	})
+
+	v := <-done
+	if v != "foo" {
+		t.Fatalf("value = %v, want %v", v, "foo")
+	}
+}
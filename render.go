@@ -0,0 +1,129 @@
+package micrologger
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"github.com/giantswarm/microerror"
+	kitlog "github.com/go-kit/log"
+)
+
+// maxErrorDepth limits how deep chains of wrapped errors are expanded, which
+// protects against cyclic chains.
+const maxErrorDepth = 32
+
+const microerrorPkgPath = "github.com/giantswarm/microerror"
+
+// processKeyVals prepares keyVals for rendering. See processStack and
+// processErrors.
+func (f stackFormatter) processKeyVals(keyVals []interface{}) []interface{} {
+	return f.processErrors(f.processStack(keyVals))
+}
+
+// processErrors renders every error value in keyVals as structured object
+// using renderError. The given keyVals are not mutated.
+func (f stackFormatter) processErrors(keyVals []interface{}) []interface{} {
+	var keyValsCopy []interface{}
+
+	for i := 1; i < len(keyVals); i += 2 {
+		// kitlog.ErrMissingValue marks missing values, see checkKeyVals,
+		// which are written as "(MISSING)".
+		err, ok := keyVals[i].(error)
+		if !ok || err == nil || err == kitlog.ErrMissingValue {
+			continue
+		}
+
+		if keyValsCopy == nil {
+			keyValsCopy = append([]interface{}{}, keyVals...)
+		}
+		keyValsCopy[i] = f.renderError(err, 0)
+	}
+
+	if keyValsCopy == nil {
+		return keyVals
+	}
+
+	return keyValsCopy
+}
+
+// renderError renders the given error as object with its message. Errors
+// created using microerror additionally carry their kind, annotation and
+// stack. Wrapped errors, also those joined with errors.Join, are rendered
+// recursively as "causes". Nil and typed nil pointer errors are rendered as
+// nil.
+func (f stackFormatter) renderError(err error, depth int) interface{} {
+	if v := reflect.ValueOf(err); err == nil || v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil
+	}
+
+	m := map[string]interface{}{
+		"message": safeError(err),
+	}
+
+	if isMicroerror(err) {
+		var jerr microerror.JSONError
+		if json.Unmarshal([]byte(microerror.JSON(err)), &jerr) == nil {
+			if jerr.Error != nil {
+				m["kind"] = jerr.Kind
+			}
+			if jerr.Annotation != "" {
+				m["annotation"] = jerr.Annotation
+			}
+			if len(jerr.Stack) != 0 {
+				var entries []stackEntry
+				for _, e := range jerr.Stack {
+					entries = append(entries, stackEntry{File: e.File, Line: e.Line})
+				}
+				m["stack"] = f.formatStack(entries)
+			}
+		}
+	}
+
+	if depth < maxErrorDepth {
+		var causes []interface{}
+		for _, c := range errorCauses(err) {
+			if r := f.renderError(c, depth+1); r != nil {
+				causes = append(causes, r)
+			}
+		}
+		if len(causes) != 0 {
+			m["causes"] = causes
+		}
+	}
+
+	return m
+}
+
+// errorCauses returns the errors directly wrapped by err. Wrappers created by
+// microerror are skipped since their information is already rendered as
+// kind, annotation and stack.
+func errorCauses(err error) []error {
+	for isMicroerror(err) {
+		u := errors.Unwrap(err)
+		if u == nil {
+			break
+		}
+		err = u
+	}
+
+	switch u := err.(type) { //nolint:errorlint
+	case interface{ Unwrap() []error }:
+		return u.Unwrap()
+	case interface{ Unwrap() error }:
+		if c := u.Unwrap(); c != nil {
+			return []error{c}
+		}
+	}
+
+	return nil
+}
+
+func isMicroerror(err error) bool {
+	t := reflect.TypeOf(err)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t.PkgPath() == microerrorPkgPath
+}
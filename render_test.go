@@ -0,0 +1,167 @@
+package micrologger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/microerror"
+	"github.com/google/go-cmp/cmp"
+)
+
+var testError = &microerror.Error{
+	Kind: "testError",
+}
+
+func Test_renderError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected map[string]interface{}
+	}{
+		{
+			name: "case 0: plain error",
+			err:  io.EOF,
+			expected: map[string]interface{}{
+				"message": "EOF",
+			},
+		},
+		{
+			name: "case 1: wrapped error",
+			err:  fmt.Errorf("reading: %w", io.EOF),
+			expected: map[string]interface{}{
+				"message": "reading: EOF",
+				"causes": []interface{}{
+					map[string]interface{}{
+						"message": "EOF",
+					},
+				},
+			},
+		},
+		{
+			name: "case 2: joined errors",
+			err:  errors.Join(io.EOF, io.ErrClosedPipe),
+			expected: map[string]interface{}{
+				"message": "EOF\nio: read/write on closed pipe",
+				"causes": []interface{}{
+					map[string]interface{}{
+						"message": "EOF",
+					},
+					map[string]interface{}{
+						"message": "io: read/write on closed pipe",
+					},
+				},
+			},
+		},
+		{
+			name: "case 3: microerror",
+			err:  microerror.Maskf(testError, "foo"),
+			expected: map[string]interface{}{
+				"message":    "test error: foo",
+				"kind":       "testError",
+				"annotation": "foo",
+				"stack":      true,
+			},
+		},
+		{
+			name: "case 4: masked wrapped error",
+			err:  microerror.Mask(fmt.Errorf("reading: %w", io.EOF)),
+			expected: map[string]interface{}{
+				"message":    "reading: EOF",
+				"kind":       "unknown",
+				"annotation": "reading: EOF",
+				"stack":      true,
+				"causes": []interface{}{
+					map[string]interface{}{
+						"message": "EOF",
+					},
+				},
+			},
+		},
+		{
+			name: "case 5: wrapped microerror",
+			err:  fmt.Errorf("reconciling: %w", microerror.Maskf(testError, "foo")),
+			expected: map[string]interface{}{
+				"message": "reconciling: test error: foo",
+				"causes": []interface{}{
+					map[string]interface{}{
+						"message":    "test error: foo",
+						"kind":       "testError",
+						"annotation": "foo",
+						"stack":      true,
+					},
+				},
+			},
+		},
+		{
+			name:     "case 6: typed nil error",
+			err:      (*microerror.Error)(nil),
+			expected: nil,
+		},
+		{
+			name: "case 7: wrapped typed nil error",
+			err:  fmt.Errorf("reading: %w", (*microerror.Error)(nil)),
+			expected: map[string]interface{}{
+				"message": "reading: <nil>",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			actual := replaceStacks(stackFormatter{}.renderError(tc.err, 0))
+
+			if !cmp.Equal(actual, tc.expected) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expected, actual))
+			}
+		})
+	}
+}
+
+func Test_MicroLogger_Log_error(t *testing.T) {
+	w := &bytes.Buffer{}
+
+	logger, err := New(Config{IOWriter: w})
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	logger.Log("err", fmt.Errorf("reading: %w", io.EOF))
+
+	var entry map[string]interface{}
+	err = json.Unmarshal(w.Bytes(), &entry)
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	m, ok := entry["err"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("err = %#v, want object", entry["err"])
+	}
+	if m["message"] != "reading: EOF" {
+		t.Fatalf("message = %#v, want %#v", m["message"], "reading: EOF")
+	}
+}
+
+// replaceStacks replaces the machine dependent stacks with true.
+func replaceStacks(v interface{}) map[string]interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	if _, ok := m["stack"]; ok {
+		m["stack"] = true
+	}
+	if causes, ok := m["causes"].([]interface{}); ok {
+		for _, c := range causes {
+			replaceStacks(c)
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,91 @@
+package micrologger
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/go-logr/logr"
+)
+
+// Handle implements logr.SlogSink. It writes records of a slog.Logger using
+// the handler returned by logr.ToSlogHandler. slog levels are mapped to the
+// levels of this package. Attributes of groups are written with dotted keys.
+func (l *LogrSink) Handle(ctx context.Context, record slog.Record) error {
+	var kvs []interface{}
+	record.Attrs(func(a slog.Attr) bool {
+		kvs = appendAttr(kvs, l.group, a)
+		return true
+	})
+
+	var values []interface{}
+	{
+		values = append(values, l.getValues(slogLevel(record.Level))...)
+		values = append(values, "message", record.Message)
+		values = append(values, kvs...)
+	}
+
+	l.log(ctx, l.keyValsWithMeta(ctx, values))
+
+	return nil
+}
+
+// WithAttrs implements logr.SlogSink.
+func (l *LogrSink) WithAttrs(attrs []slog.Attr) logr.SlogSink {
+	var kvs []interface{}
+	for _, a := range attrs {
+		kvs = appendAttr(kvs, l.group, a)
+	}
+
+	return l.WithValues(kvs...).(*LogrSink)
+}
+
+// WithGroup implements logr.SlogSink.
+func (l *LogrSink) WithGroup(name string) logr.SlogSink {
+	sinkCopy := l.deepCopy()
+	sinkCopy.group = groupKey(l.group, name)
+	return sinkCopy
+}
+
+// appendAttr appends the key and the resolved value of the given attribute to
+// kvs. Groups are flattened using dotted keys and empty attributes are
+// omitted, just like slog handlers do.
+func appendAttr(kvs []interface{}, group string, a slog.Attr) []interface{} {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return kvs
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		group = groupKey(group, a.Key)
+		for _, ga := range a.Value.Group() {
+			kvs = appendAttr(kvs, group, ga)
+		}
+		return kvs
+	}
+
+	return append(kvs, groupKey(group, a.Key), a.Value.Any())
+}
+
+func groupKey(group string, key string) string {
+	if group == "" {
+		return key
+	}
+	if key == "" {
+		return group
+	}
+
+	return group + "." + key
+}
+
+func slogLevel(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "error"
+	case level >= slog.LevelWarn:
+		return "warning"
+	case level >= slog.LevelInfo:
+		return "info"
+	default:
+		return "debug"
+	}
+}
@@ -0,0 +1,148 @@
+package micrologger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+)
+
+type testMarshaler struct {
+	name string
+}
+
+func (m *testMarshaler) MarshalLog() interface{} {
+	return map[string]interface{}{"name": m.name}
+}
+
+func Test_LogrSink_slog(t *testing.T) {
+	testCases := []struct {
+		name     string
+		log      func(l *slog.Logger) int
+		expected map[string]interface{}
+	}{
+		{
+			name: "case 0: info with attributes",
+			log: func(l *slog.Logger) int {
+				l.Info("test", "foo", "bar", "count", 3)
+				return line()
+			},
+			expected: map[string]interface{}{
+				"level":   "info",
+				"message": "test",
+				"name":    "",
+				"foo":     "bar",
+				"count":   float64(3),
+			},
+		},
+		{
+			name: "case 1: groups and attributes",
+			log: func(l *slog.Logger) int {
+				l.With("foo", "bar").WithGroup("req").With("id", "1").Warn("test", slog.Group("user", "name", "jane"))
+				return line()
+			},
+			expected: map[string]interface{}{
+				"level":         "warning",
+				"message":       "test",
+				"name":          "",
+				"foo":           "bar",
+				"req.id":        "1",
+				"req.user.name": "jane",
+			},
+		},
+		{
+			name: "case 2: error level",
+			log: func(l *slog.Logger) int {
+				l.Error("test", "foo", "bar")
+				return line()
+			},
+			expected: map[string]interface{}{
+				"level":   "error",
+				"message": "test",
+				"name":    "",
+				"foo":     "bar",
+			},
+		},
+		{
+			name: "case 3: debug level",
+			log: func(l *slog.Logger) int {
+				l.Debug("test")
+				return line()
+			},
+			expected: map[string]interface{}{
+				"level":   "debug",
+				"message": "test",
+				"name":    "",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &bytes.Buffer{}
+
+			logger, err := New(Config{IOWriter: w})
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			l := slog.New(logr.ToSlogHandler(logr.New(logger.AsSink(4))))
+
+			// The logging call is on the line before the one returned.
+			expectedCaller := fmt.Sprintf("/slog_test.go:%d", tc.log(l)-1)
+
+			var entry map[string]interface{}
+			err = json.Unmarshal(w.Bytes(), &entry)
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			caller, _ := entry[KeyCaller].(string)
+			if !strings.HasSuffix(caller, expectedCaller) {
+				t.Fatalf("caller = %#q, want suffix %#q", caller, expectedCaller)
+			}
+
+			delete(entry, KeyCaller)
+			delete(entry, "time")
+			if !cmp.Equal(entry, tc.expected) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expected, entry))
+			}
+		})
+	}
+}
+
+func Test_LogrSink_Marshaler(t *testing.T) {
+	w := &bytes.Buffer{}
+
+	logger, err := New(Config{IOWriter: w})
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	var nilMarshaler *testMarshaler
+	l := logr.New(logger.AsSink(1)).WithValues("object", &testMarshaler{name: "with"})
+	l.Info("test", "value", &testMarshaler{name: "info"}, "nil", nilMarshaler)
+
+	var entry map[string]interface{}
+	err = json.Unmarshal(w.Bytes(), &entry)
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	expected := map[string]interface{}{
+		"object": map[string]interface{}{"name": "with"},
+		"value":  map[string]interface{}{"name": "info"},
+		"nil":    "PANIC=runtime error: invalid memory address or nil pointer dereference",
+	}
+	for k, v := range expected {
+		if !cmp.Equal(entry[k], v) {
+			t.Fatalf("%s = %#v, want %#v", k, entry[k], v)
+		}
+	}
+}
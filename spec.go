@@ -16,18 +16,34 @@ type Logger interface {
 	// error level. The error stack trace is written as "stack" value log
 	// entry.
 	Errorf(ctx context.Context, err error, format string, params ...interface{})
+	// Enabled returns whether records of the given level, one of debug, info,
+	// warning, error or fatal, may be written by calls made with ctx. It is
+	// useful to guard expensive diagnostics. See also Lazy.
+	Enabled(ctx context.Context, level string) bool
 	// Log takes a sequence of alternating key/value pairs which are used
-	// to create the log message structure.
+	// to create the log message structure. Values of type error are
+	// rendered as objects carrying their message, microerror kind,
+	// annotation and stack as well as their wrapped causes.
 	Log(keyVals ...interface{})
 	// LogCtx is the same as Log but additionally taking a context which
 	// may contain additional key-value pairs that are added to the log
 	// issuance, if any.
 	LogCtx(ctx context.Context, keyVals ...interface{})
+	// Named returns a new logger with the given name appended to its dotted
+	// name, e.g. "resource.endpoint". The name is written under the "name"
+	// key and can be used as activation key.
+	Named(name string) Logger
+	// LogFields writes a record of the given level and message with the
+	// given typed fields, e.g. String("resource", name). The record is
+	// written exactly like the equivalent call to LogCtx.
+	LogFields(ctx context.Context, level string, message string, fields ...Field)
 	// With returns a new contextual logger with keyVals appended to those
 	// passed to calls to Log. If logger is also a contextual logger
 	// created by With, keyVals is appended to the existing context.
 	With(keyVals ...interface{}) Logger
 	// WithIncreasedCallerDepth is useful when wrapping with another
 	// interface to pass it as dependency to a library outside Giant Swarm.
+	// Wrappers can alternatively be marked using Helper or
+	// RegisterHelperPackage so that their frames are skipped automatically.
 	WithIncreasedCallerDepth() Logger
 }
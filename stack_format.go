@@ -0,0 +1,174 @@
+package micrologger
+
+import (
+	"path"
+	"runtime"
+	"strings"
+)
+
+const (
+	// PackageStd can be used in StackConfig.CollapsePackages to collapse
+	// frames of the standard library.
+	PackageStd = "std"
+)
+
+type StackConfig struct {
+	// CollapsePackages lists import paths of packages whose frames are
+	// collapsed. Consecutive frames of matching packages, including their
+	// sub packages, are rendered as a single {"collapsed": n} frame. Use
+	// PackageStd to collapse frames of the standard library. The caller key
+	// skips frames of matching packages.
+	CollapsePackages []string
+	// MaxFrames caps the number of frames rendered per stack. Omitted frames
+	// are rendered as a single {"truncated": n} frame. Zero means no limit.
+	MaxFrames int
+	// TrimGoPaths removes GOPATH, GOROOT and module cache prefixes from file
+	// paths so that they start with the import path.
+	TrimGoPaths bool
+	// TrimPrefixes are removed from file paths, e.g. the directory binaries
+	// are built in. The first matching prefix is removed.
+	TrimPrefixes []string
+}
+
+// stackFormatter renders stack frames as configured by StackConfig. The zero
+// value renders stack frames unchanged.
+type stackFormatter struct {
+	config StackConfig
+}
+
+type stackEntry struct {
+	File string
+	Line int
+}
+
+func (f stackFormatter) enabled() bool {
+	c := f.config
+	return len(c.CollapsePackages) != 0 || c.MaxFrames != 0 || c.TrimGoPaths || len(c.TrimPrefixes) != 0
+}
+
+// formatStack renders the given stack entries, collapsing, truncating and
+// trimming them as configured.
+func (f stackFormatter) formatStack(entries []stackEntry) []interface{} {
+	var frames []interface{}
+
+	var collapsed int
+	for _, e := range entries {
+		if f.collapsed(fileImportPath(e.File)) {
+			collapsed++
+			continue
+		}
+
+		if collapsed != 0 {
+			frames = append(frames, map[string]interface{}{"collapsed": collapsed})
+			collapsed = 0
+		}
+		frames = append(frames, map[string]interface{}{
+			"file": f.trimFile(e.File),
+			"line": e.Line,
+		})
+	}
+	if collapsed != 0 {
+		frames = append(frames, map[string]interface{}{"collapsed": collapsed})
+	}
+
+	if f.config.MaxFrames > 0 && len(frames) > f.config.MaxFrames {
+		truncated := len(frames) - f.config.MaxFrames
+		frames = append(frames[:f.config.MaxFrames], map[string]interface{}{"truncated": truncated})
+	}
+
+	return frames
+}
+
+// formatStackObject formats the "stack" list of the given microerror JSON
+// object in place.
+func (f stackFormatter) formatStackObject(m map[string]interface{}) {
+	list, ok := m["stack"].([]interface{})
+	if !ok {
+		return
+	}
+
+	var entries []stackEntry
+	for _, v := range list {
+		o, ok := v.(map[string]interface{})
+		if !ok {
+			return
+		}
+		file, _ := o["file"].(string)
+		line, _ := o["line"].(float64)
+
+		entries = append(entries, stackEntry{File: file, Line: int(line)})
+	}
+
+	m["stack"] = f.formatStack(entries)
+}
+
+// collapsedFrame returns whether the given frame belongs to a collapsed
+// package.
+func (f stackFormatter) collapsedFrame(frame runtime.Frame) bool {
+	return f.collapsed(funcPackage(frame.Function))
+}
+
+func (f stackFormatter) collapsed(importPath string) bool {
+	for _, p := range f.config.CollapsePackages {
+		if p == PackageStd && isStdImportPath(importPath) {
+			return true
+		}
+		if importPath == p || strings.HasPrefix(importPath, p+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f stackFormatter) trimFile(file string) string {
+	for _, p := range f.config.TrimPrefixes {
+		if strings.HasPrefix(file, p) {
+			file = strings.TrimPrefix(file, p)
+			break
+		}
+	}
+
+	if f.config.TrimGoPaths {
+		if i := strings.LastIndex(file, "/pkg/mod/"); i >= 0 {
+			file = file[i+len("/pkg/mod/"):]
+		} else if i := strings.LastIndex(file, "/src/"); i >= 0 {
+			file = file[i+len("/src/"):]
+		}
+	}
+
+	return file
+}
+
+// fileImportPath derives the import path of the package the given file
+// belongs to from its GOPATH, GOROOT or module cache location. Module
+// versions are removed.
+func fileImportPath(file string) string {
+	if i := strings.LastIndex(file, "/pkg/mod/"); i >= 0 {
+		file = file[i+len("/pkg/mod/"):]
+
+		if j := strings.Index(file, "@"); j >= 0 {
+			k := strings.Index(file[j:], "/")
+			if k < 0 {
+				k = len(file[j:])
+			}
+			file = file[:j] + file[j+k:]
+		}
+	} else if i := strings.LastIndex(file, "/src/"); i >= 0 {
+		file = file[i+len("/src/"):]
+	}
+
+	return path.Dir(file)
+}
+
+// isStdImportPath reports whether the given import path belongs to the
+// standard library, whose import paths do not contain a dot in their first
+// element.
+func isStdImportPath(importPath string) bool {
+	if strings.HasPrefix(importPath, "/") || importPath == "." {
+		return false
+	}
+
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
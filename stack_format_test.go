@@ -0,0 +1,122 @@
+package micrologger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_stackFormatter_formatStack(t *testing.T) {
+	entries := []stackEntry{
+		{File: "/Users/kopiczko/go/src/github.com/giantswarm/opsctl/service/github/github.go", Line: 143},
+		{File: "/usr/local/go/src/net/http/server.go", Line: 2000},
+		{File: "/usr/local/go/src/net/http/server.go", Line: 3000},
+		{File: "/Users/kopiczko/go/pkg/mod/github.com/giantswarm/backoff@v0.0.0-20190913091243-4dd491125192/retry.go", Line: 23},
+		{File: "/Users/kopiczko/go/src/github.com/giantswarm/opsctl/command/deploy/command.go", Line: 253},
+	}
+
+	testCases := []struct {
+		name     string
+		config   StackConfig
+		expected []interface{}
+	}{
+		{
+			name:   "case 0: zero value renders frames unchanged",
+			config: StackConfig{},
+			expected: []interface{}{
+				map[string]interface{}{"file": entries[0].File, "line": 143},
+				map[string]interface{}{"file": entries[1].File, "line": 2000},
+				map[string]interface{}{"file": entries[2].File, "line": 3000},
+				map[string]interface{}{"file": entries[3].File, "line": 23},
+				map[string]interface{}{"file": entries[4].File, "line": 253},
+			},
+		},
+		{
+			name: "case 1: trim go paths",
+			config: StackConfig{
+				TrimGoPaths: true,
+			},
+			expected: []interface{}{
+				map[string]interface{}{"file": "github.com/giantswarm/opsctl/service/github/github.go", "line": 143},
+				map[string]interface{}{"file": "net/http/server.go", "line": 2000},
+				map[string]interface{}{"file": "net/http/server.go", "line": 3000},
+				map[string]interface{}{"file": "github.com/giantswarm/backoff@v0.0.0-20190913091243-4dd491125192/retry.go", "line": 23},
+				map[string]interface{}{"file": "github.com/giantswarm/opsctl/command/deploy/command.go", "line": 253},
+			},
+		},
+		{
+			name: "case 2: trim prefixes and collapse packages",
+			config: StackConfig{
+				CollapsePackages: []string{PackageStd, "github.com/giantswarm/backoff"},
+				TrimPrefixes:     []string{"/Users/kopiczko/go/src/github.com/giantswarm/"},
+			},
+			expected: []interface{}{
+				map[string]interface{}{"file": "opsctl/service/github/github.go", "line": 143},
+				map[string]interface{}{"collapsed": 3},
+				map[string]interface{}{"file": "opsctl/command/deploy/command.go", "line": 253},
+			},
+		},
+		{
+			name: "case 3: cap frames",
+			config: StackConfig{
+				MaxFrames:   2,
+				TrimGoPaths: true,
+			},
+			expected: []interface{}{
+				map[string]interface{}{"file": "github.com/giantswarm/opsctl/service/github/github.go", "line": 143},
+				map[string]interface{}{"file": "net/http/server.go", "line": 2000},
+				map[string]interface{}{"truncated": 3},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			actual := stackFormatter{config: tc.config}.formatStack(entries)
+
+			if !cmp.Equal(actual, tc.expected) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expected, actual))
+			}
+		})
+	}
+}
+
+func Test_MicroLogger_Stack(t *testing.T) {
+	w := &bytes.Buffer{}
+
+	c := Config{
+		IOWriter: w,
+		Stack: StackConfig{
+			CollapsePackages: []string{PackageStd},
+			TrimGoPaths:      true,
+			TrimPrefixes:     []string{"github.com/giantswarm/"},
+		},
+	}
+
+	logger, err := New(c)
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	logger.Log("stack", `{"kind":"unknown","stack":[{"file":"/Users/kopiczko/go/src/github.com/giantswarm/opsctl/service/github/github.go","line":143}]}`)
+
+	var entry map[string]interface{}
+	err = json.Unmarshal(w.Bytes(), &entry)
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	caller := entry["caller"].(string)
+	if strings.HasPrefix(caller, "github.com/giantswarm/") || !strings.Contains(caller, "/stack_format_test.go:") {
+		t.Fatalf("caller = %#q, want trimmed", caller)
+	}
+
+	file := entry["stack"].(map[string]interface{})["stack"].([]interface{})[0].(map[string]interface{})["file"]
+	if file != "github.com/giantswarm/opsctl/service/github/github.go" {
+		t.Fatalf("file = %#q, want trimmed", file)
+	}
+}
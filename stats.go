@@ -0,0 +1,36 @@
+package micrologger
+
+import (
+	"sync/atomic"
+)
+
+// Stats are failure counters of a logger, shared with all loggers derived
+// from it using With, Named and the like.
+type Stats struct {
+	// EncodeFailures counts records which could not be encoded, e.g. because
+	// of unsupported values like NaN.
+	EncodeFailures uint64
+	// WriteFailures counts records which could not be written to the
+	// IOWriter, as well as failed flushes.
+	WriteFailures uint64
+}
+
+type stats struct {
+	encodeFailures atomic.Uint64
+	writeFailures  atomic.Uint64
+}
+
+func (s *stats) count(err error) {
+	if IsEncodeFailed(err) {
+		s.encodeFailures.Add(1)
+	} else if IsWriteFailed(err) {
+		s.writeFailures.Add(1)
+	}
+}
+
+func (s *stats) snapshot() Stats {
+	return Stats{
+		EncodeFailures: s.encodeFailures.Load(),
+		WriteFailures:  s.writeFailures.Load(),
+	}
+}
@@ -0,0 +1,106 @@
+package micrologger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type failingWriter struct{}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("test write error")
+}
+
+func Test_MicroLogger_ErrorHandler(t *testing.T) {
+	testCases := []struct {
+		name             string
+		failWrite        bool
+		log              func(l Logger)
+		fallback         bool
+		expectedErr      func(err error) bool
+		expectedFallback bool
+		expectedStats    Stats
+	}{
+		{
+			name: "case 0: write failure",
+			log: func(l Logger) {
+				l.Debug(context.Background(), "test")
+			},
+			failWrite:     true,
+			expectedErr:   IsWriteFailed,
+			expectedStats: Stats{WriteFailures: 1},
+		},
+		{
+			name: "case 1: write failure with fallback",
+			log: func(l Logger) {
+				l.With("foo", "bar").Debug(context.Background(), "test")
+			},
+			failWrite:        true,
+			fallback:         true,
+			expectedErr:      IsWriteFailed,
+			expectedFallback: true,
+			expectedStats:    Stats{WriteFailures: 1},
+		},
+		{
+			name: "case 2: encode failure",
+			log: func(l Logger) {
+				l.Log("message", "test", "nan", math.NaN())
+			},
+			fallback:      true,
+			expectedErr:   IsEncodeFailed,
+			expectedStats: Stats{EncodeFailures: 1},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var handled []error
+			var keyVals []interface{}
+
+			config := Config{
+				IOWriter: &bytes.Buffer{},
+				ErrorHandler: func(err error, kvs []interface{}) {
+					handled = append(handled, err)
+					keyVals = kvs
+				},
+			}
+			if tc.failWrite {
+				config.IOWriter = failingWriter{}
+			}
+
+			fallback := &bytes.Buffer{}
+			if tc.fallback {
+				config.FallbackWriter = fallback
+			}
+
+			logger, err := New(config)
+			if err != nil {
+				t.Fatalf("err = %v, want %v", err, nil)
+			}
+
+			tc.log(logger)
+
+			if len(handled) != 1 || !tc.expectedErr(handled[0]) {
+				t.Fatalf("handled = %v, want one matching error", handled)
+			}
+			if v, _ := valueFor(keyVals, "message"); v != "test" {
+				t.Fatalf("message = %v, want %v", v, "test")
+			}
+
+			written := strings.Contains(fallback.String(), `"message":"test"`)
+			if written != tc.expectedFallback {
+				t.Fatalf("fallback = %#q, want written %v", fallback.String(), tc.expectedFallback)
+			}
+
+			stats := logger.Stats()
+			if stats != tc.expectedStats {
+				t.Fatalf("stats = %#v, want %#v", stats, tc.expectedStats)
+			}
+		})
+	}
+}
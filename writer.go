@@ -0,0 +1,69 @@
+package micrologger
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// syncWriter serializes writes to the underlying io.Writer and allows to
+// flush and swap it in between.
+type syncWriter struct {
+	mutex sync.Mutex
+	w     io.Writer
+}
+
+func newSyncWriter(w io.Writer) *syncWriter {
+	return &syncWriter{
+		w: w,
+	}
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.w.Write(p)
+}
+
+// Flush flushes the underlying io.Writer in case it buffers writes, e.g.
+// *bufio.Writer, or syncs it to stable storage in case it is a file, e.g.
+// *os.File.
+func (s *syncWriter) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return flush(s.w)
+}
+
+// Swap flushes the underlying io.Writer and replaces it with w. Writes in
+// progress complete before.
+func (s *syncWriter) Swap(w io.Writer) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := flush(s.w)
+	s.w = w
+
+	return err
+}
+
+// flush flushes w in case it buffers writes, or syncs it in case it is a
+// regular file. Files which are no regular files, like pipes and terminals,
+// e.g. os.Stdout, do not support syncing and are left alone.
+func flush(w io.Writer) error {
+	switch w := w.(type) {
+	case interface{ Flush() error }:
+		return w.Flush()
+	case interface{ Sync() error }:
+		if f, ok := w.(interface{ Stat() (os.FileInfo, error) }); ok {
+			info, err := f.Stat()
+			if err != nil || !info.Mode().IsRegular() {
+				return nil
+			}
+		}
+		return w.Sync()
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package micrologger
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func Test_MicroLogger_SetOutput(t *testing.T) {
+	before := &bytes.Buffer{}
+	after := &bytes.Buffer{}
+
+	logger, err := New(Config{IOWriter: before})
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+	child := logger.With("foo", "bar")
+
+	child.Debug(context.Background(), "before")
+
+	logger.SetOutput(after)
+
+	child.Debug(context.Background(), "after")
+	logger.Debug(context.Background(), "after")
+
+	if n := strings.Count(before.String(), "\n"); n != 1 {
+		t.Fatalf("records before = %d, want %d", n, 1)
+	}
+	if n := strings.Count(after.String(), `"message":"after"`); n != 2 {
+		t.Fatalf("records after = %d, want %d", n, 2)
+	}
+}
+
+func Test_MicroLogger_SetOutput_concurrent(t *testing.T) {
+	logger, err := New(Config{IOWriter: &bytes.Buffer{}})
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.With("foo", "bar").Debug(context.Background(), "test")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				logger.SetOutput(&bytes.Buffer{})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if stats := logger.Stats(); stats != (Stats{}) {
+		t.Fatalf("stats = %#v, want %#v", stats, Stats{})
+	}
+}
+
+func Test_MicroLogger_SetOutput_pipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	var handled []error
+	logger, err := New(Config{
+		IOWriter: w,
+		ErrorHandler: func(err error, keyVals []interface{}) {
+			handled = append(handled, err)
+		},
+	})
+	if err != nil {
+		t.Fatalf("err = %v, want %v", err, nil)
+	}
+
+	// Pipes cannot be synced, which must not be reported as failure.
+	logger.SetOutput(&bytes.Buffer{})
+
+	if len(handled) != 0 {
+		t.Fatalf("handled = %v, want none", handled)
+	}
+	if stats := logger.Stats(); stats != (Stats{}) {
+		t.Fatalf("stats = %#v, want %#v", stats, Stats{})
+	}
+}